@@ -0,0 +1,462 @@
+package main
+
+import (
+	"ecom-be/config"
+	"ecom-be/controllers"
+	"ecom-be/mailer"
+	"ecom-be/middleware"
+	"ecom-be/models"
+	"ecom-be/oidc"
+	"ecom-be/push"
+	"ecom-be/routes"
+	"ecom-be/search"
+	"ecom-be/storage"
+	"ecom-be/throttle"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// Exit code yang bisa dicek oleh script
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) int
+}
+
+func commands() []command {
+	return []command{
+		{"serve", "Menjalankan HTTP server (default)", runServe},
+		{"migrate", "Menjalankan migrasi skema database", runMigrate},
+		{"create-admin", "Membuat akun admin baru", runCreateAdmin},
+		{"seed", "Mengisi data contoh (produk dan akun demo)", runSeed},
+		{"reindex", "Membangun ulang index pencarian produk dari database", runReindex},
+		{"reset-password", "Mengganti password user dan mencabut semua sesinya", runResetPassword},
+		{"generate-jwt-key", "Membuat private key baru untuk rotasi JWT (RS256/EdDSA)", runGenerateJWTKey},
+	}
+}
+
+// run menjalankan subcommand sesuai argumen dan mengembalikan exit code
+func run(args []string) int {
+	if len(args) == 0 {
+		return runServe(nil)
+	}
+
+	name := args[0]
+	if name == "help" || name == "-h" || name == "--help" {
+		printUsage()
+		return exitOK
+	}
+
+	for _, cmd := range commands() {
+		if cmd.name == name {
+			return cmd.run(args[1:])
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Perintah tidak dikenal: %s\n\n", name)
+	printUsage()
+	return exitUsage
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Penggunaan: ecom-be <perintah> [opsi]")
+	fmt.Fprintln(os.Stderr, "\nPerintah:")
+	for _, cmd := range commands() {
+		fmt.Fprintf(os.Stderr, "  %-16s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(os.Stderr, "\nGunakan \"ecom-be <perintah> -h\" untuk melihat opsi tiap perintah.")
+}
+
+// parseFlags mem-parse flag subcommand; ok=false berarti caller harus keluar dengan code
+func parseFlags(fs *flag.FlagSet, args []string) (code int, ok bool) {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK, false
+		}
+		return exitUsage, false
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "Argumen tidak dikenal: %s\n", strings.Join(fs.Args(), " "))
+		fs.Usage()
+		return exitUsage, false
+	}
+	return exitOK, true
+}
+
+func runServe(args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	port := fs.String("port", "", "port HTTP (default dari PORT)")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	// Load environment variables
+	config.LoadEnv()
+
+	// Muat key penanda tangan JWT
+	if err := middleware.LoadSigningKeys(); err != nil {
+		fmt.Fprintf(os.Stderr, "Gagal memuat signing key JWT: %v\n", err)
+		return exitError
+	}
+
+	// Pilih mailer sesuai MAIL_DRIVER dan sender push sesuai PUSH_DRIVER
+	mailer.Setup()
+	if err := push.Setup(); err != nil {
+		fmt.Fprintf(os.Stderr, "Gagal menyiapkan push notification: %v\n", err)
+		return exitError
+	}
+
+	// Pilih penyimpanan file sesuai STORAGE_DRIVER
+	if err := storage.Setup(); err != nil {
+		fmt.Fprintf(os.Stderr, "Gagal menyiapkan storage: %v\n", err)
+		return exitError
+	}
+
+	// Daftarkan penyedia login OIDC dari OIDC_PROVIDERS
+	oidc.Setup()
+
+	// Connect ke database
+	config.ConnectDatabase()
+
+	// Buka index pencarian produk; dibangun dari database jika belum ada
+	if code := setupSearchIndex(); code != exitOK {
+		return code
+	}
+
+	// Pakai index yang dibangun ulang oleh perintah reindex
+	controllers.StartSearchIndexWatch(10 * time.Second)
+
+	// Pilih store throttle login sesuai LOGIN_THROTTLE_STORE
+	throttle.Setup(config.DB)
+
+	// Bersihkan daftar token yang dicabut secara berkala
+	middleware.StartRevocationCleanup(time.Hour)
+
+	// Hapus keranjang tamu yang sudah lama tidak dipakai
+	controllers.StartGuestCartCleanup(time.Hour)
+
+	// Setup router
+	r := routes.SetupRouter()
+
+	// Set port
+	if *port == "" {
+		*port = os.Getenv("PORT")
+	}
+
+	// Jalankan server
+	log.Printf("Server berjalan di port %s", *port)
+	if err := r.Run(":" + *port); err != nil {
+		fmt.Fprintf(os.Stderr, "Gagal menjalankan server: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
+
+func runMigrate(args []string) int {
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	config.LoadEnv()
+	// ConnectDatabase sudah menjalankan migrasi
+	config.ConnectDatabase()
+
+	fmt.Println("Migrasi database selesai")
+	return exitOK
+}
+
+func runCreateAdmin(args []string) int {
+	fs := flag.NewFlagSet("create-admin", flag.ContinueOnError)
+	email := fs.String("email", "", "email admin (wajib)")
+	name := fs.String("name", "Admin", "nama admin")
+	password := fs.String("password", "", "password admin (dibuat acak jika kosong)")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	if *email == "" {
+		fmt.Fprintln(os.Stderr, "--email wajib diisi")
+		fs.Usage()
+		return exitUsage
+	}
+
+	generated := false
+	if *password == "" {
+		random, err := middleware.GenerateOpaqueToken()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Gagal membuat password: %v\n", err)
+			return exitError
+		}
+		*password = random[:16]
+		generated = true
+	} else if len(*password) < 6 {
+		fmt.Fprintln(os.Stderr, "--password minimal 6 karakter")
+		return exitUsage
+	}
+
+	config.LoadEnv()
+	config.ConnectDatabase()
+
+	var count int64
+	config.DB.Model(&models.User{}).Where("email = ?", *email).Count(&count)
+	if count > 0 {
+		fmt.Fprintf(os.Stderr, "User dengan email %s sudah ada\n", *email)
+		return exitError
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(*password), bcrypt.DefaultCost)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Gagal meng-hash password: %v\n", err)
+		return exitError
+	}
+
+	now := time.Now()
+	admin := models.User{
+		Name:            *name,
+		Email:           *email,
+		Password:        string(hashedPassword),
+		Role:            "admin",
+		EmailVerifiedAt: &now,
+	}
+	if err := config.DB.Create(&admin).Error; err != nil {
+		fmt.Fprintf(os.Stderr, "Gagal membuat admin: %v\n", err)
+		return exitError
+	}
+
+	fmt.Printf("Admin %s berhasil dibuat (id %d)\n", admin.Email, admin.ID)
+	if generated {
+		fmt.Printf("Password: %s\n", *password)
+	}
+	return exitOK
+}
+
+func runSeed(args []string) int {
+	fs := flag.NewFlagSet("seed", flag.ContinueOnError)
+	force := fs.Bool("force", false, "izinkan seed saat GO_ENV=production")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	config.LoadEnv()
+	if os.Getenv("GO_ENV") == "production" && !*force {
+		fmt.Fprintln(os.Stderr, "Seed berisi akun demo dengan password lemah; gunakan --force untuk menjalankannya di production")
+		return exitError
+	}
+
+	config.ConnectDatabase()
+
+	if err := config.DB.Transaction(seedData); err != nil {
+		fmt.Fprintf(os.Stderr, "Gagal mengisi data contoh: %v\n", err)
+		return exitError
+	}
+
+	// Produk contoh perlu masuk ke index pencarian
+	if err := search.Setup(); err != nil {
+		fmt.Fprintf(os.Stderr, "Gagal membuka index pencarian: %v\n", err)
+		return exitError
+	}
+	if _, err := controllers.RebuildSearchIndex(); err != nil {
+		fmt.Fprintf(os.Stderr, "Gagal membangun index pencarian: %v\n", err)
+		return exitError
+	}
+
+	fmt.Println("Data contoh berhasil diisi")
+	return exitOK
+}
+
+// seedData mengisi data yang sama dengan contoh SQL di README; aman dijalankan berulang
+func seedData(tx *gorm.DB) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	users := []models.User{
+		{Name: "Admin", Email: "admin@example.com", Role: "admin"},
+		{Name: "User", Email: "user@example.com", Role: "user"},
+	}
+	for _, user := range users {
+		user.Password = string(hashedPassword)
+		user.EmailVerifiedAt = &now
+		if err := tx.Where(models.User{Email: user.Email}).FirstOrCreate(&user).Error; err != nil {
+			return err
+		}
+	}
+
+	products := []models.Product{
+		{Name: "Smartphone XYZ", Description: "Smartphone canggih dengan fitur terbaru", Price: 2500000, Stock: 50},
+		{Name: "Laptop ABC", Description: "Laptop ringan dengan performa tinggi", Price: 8000000, Stock: 20},
+		{Name: "Headphone Premium", Description: "Headphone dengan kualitas suara terbaik", Price: 1200000, Stock: 100},
+	}
+	for _, product := range products {
+		if err := tx.Where(models.Product{Name: product.Name}).FirstOrCreate(&product).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func runReindex(args []string) int {
+	fs := flag.NewFlagSet("reindex", flag.ContinueOnError)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	config.LoadEnv()
+	if err := search.Setup(); err != nil {
+		fmt.Fprintf(os.Stderr, "Gagal membuka index pencarian: %v\n", err)
+		return exitError
+	}
+	config.ConnectDatabase()
+
+	// Server yang sedang berjalan memakai index baru dalam beberapa detik
+	count, err := controllers.RebuildSearchIndex()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Gagal membangun index pencarian: %v\n", err)
+		return exitError
+	}
+
+	fmt.Printf("Index pencarian dibangun ulang dengan %d produk\n", count)
+	return exitOK
+}
+
+// setupSearchIndex membuka index pencarian dan membangunnya jika masih kosong,
+// mis. saat pertama kali dijalankan atau setelah SEARCH_LANGUAGES diubah
+func setupSearchIndex() int {
+	if err := search.Setup(); err != nil {
+		fmt.Fprintf(os.Stderr, "Gagal membuka index pencarian: %v\n", err)
+		return exitError
+	}
+	empty, err := search.Default.Empty()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Gagal membuka index pencarian: %v\n", err)
+		return exitError
+	}
+	if !empty {
+		return exitOK
+	}
+
+	count, err := controllers.RebuildSearchIndex()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Gagal membangun index pencarian: %v\n", err)
+		return exitError
+	}
+	log.Printf("Index pencarian dibangun dengan %d produk", count)
+	return exitOK
+}
+
+func runResetPassword(args []string) int {
+	fs := flag.NewFlagSet("reset-password", flag.ContinueOnError)
+	email := fs.String("email", "", "email user (wajib)")
+	password := fs.String("password", "", "password baru (wajib, minimal 6 karakter)")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	if *email == "" || *password == "" {
+		fmt.Fprintln(os.Stderr, "--email dan --password wajib diisi")
+		fs.Usage()
+		return exitUsage
+	}
+	if len(*password) < 6 {
+		fmt.Fprintln(os.Stderr, "--password minimal 6 karakter")
+		return exitUsage
+	}
+
+	config.LoadEnv()
+	config.ConnectDatabase()
+
+	var user models.User
+	if err := config.DB.Where("email = ?", *email).First(&user).Error; err != nil {
+		fmt.Fprintf(os.Stderr, "User dengan email %s tidak ditemukan\n", *email)
+		return exitError
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(*password), bcrypt.DefaultCost)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Gagal meng-hash password: %v\n", err)
+		return exitError
+	}
+
+	if err := config.DB.Model(&user).Update("password", string(hashedPassword)).Error; err != nil {
+		fmt.Fprintf(os.Stderr, "Gagal mengganti password: %v\n", err)
+		return exitError
+	}
+
+	if err := middleware.RevokeAllUserTokens(user.ID, ""); err != nil {
+		fmt.Fprintf(os.Stderr, "Password diganti, tetapi gagal mencabut sesi: %v\n", err)
+		return exitError
+	}
+
+	fmt.Printf("Password %s berhasil diganti, semua sesi dicabut\n", user.Email)
+	return exitOK
+}
+
+func runGenerateJWTKey(args []string) int {
+	fs := flag.NewFlagSet("generate-jwt-key", flag.ContinueOnError)
+	alg := fs.String("alg", "RS256", "algoritma key: RS256 atau EdDSA")
+	dir := fs.String("dir", "", "folder key (default dari JWT_KEYS_DIR)")
+	kid := fs.String("kid", "", "key ID (default berdasarkan waktu sekarang)")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	config.LoadEnv()
+
+	if *dir == "" {
+		*dir = os.Getenv("JWT_KEYS_DIR")
+	}
+	if *dir == "" {
+		fmt.Fprintln(os.Stderr, "--dir atau JWT_KEYS_DIR wajib diisi")
+		return exitUsage
+	}
+	if *kid == "" {
+		*kid = time.Now().UTC().Format("20060102-150405")
+	}
+
+	data, err := middleware.GeneratePrivateKeyPEM(*alg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Gagal membuat key: %v\n", err)
+		return exitUsage
+	}
+
+	if err := os.MkdirAll(*dir, 0o700); err != nil {
+		fmt.Fprintf(os.Stderr, "Gagal membuat folder key: %v\n", err)
+		return exitError
+	}
+
+	path := filepath.Join(*dir, *kid+".pem")
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Gagal menulis key: %v\n", err)
+		return exitError
+	}
+	defer file.Close()
+
+	if _, err := file.Write(data); err != nil {
+		fmt.Fprintf(os.Stderr, "Gagal menulis key: %v\n", err)
+		return exitError
+	}
+
+	fmt.Printf("Key %s dibuat di %s\n", *kid, path)
+	fmt.Printf("Set JWT_ACTIVE_KID=%s lalu restart server untuk mulai memakainya\n", *kid)
+	return exitOK
+}
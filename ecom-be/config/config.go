@@ -1,8 +1,11 @@
 package config
 
 import (
+	"errors"
 	"log"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -19,6 +22,11 @@ func LoadEnv() {
 		}
 	}
 
+	// Jangan jalan di production dengan secret JWT bawaan
+	if err := checkJWTSecret(); err != nil {
+		log.Fatalf("Konfigurasi JWT tidak aman: %v", err)
+	}
+
 	// Set default values jika environment variable tidak ada
 	if os.Getenv("DB_HOST") == "" {
 		os.Setenv("DB_HOST", "localhost")
@@ -38,4 +46,55 @@ func LoadEnv() {
 	if os.Getenv("PORT") == "" {
 		os.Setenv("PORT", "8080")
 	}
-} 
\ No newline at end of file
+	if os.Getenv("ACCESS_TOKEN_TTL") == "" {
+		os.Setenv("ACCESS_TOKEN_TTL", "15m")
+	}
+	if os.Getenv("REFRESH_TOKEN_TTL") == "" {
+		os.Setenv("REFRESH_TOKEN_TTL", "720h")
+	}
+	if os.Getenv("APP_URL") == "" {
+		os.Setenv("APP_URL", "http://localhost:8080")
+	}
+	if os.Getenv("TOTP_ISSUER") == "" {
+		os.Setenv("TOTP_ISSUER", "Ecommerce")
+	}
+}
+
+// GetDuration membaca durasi (mis. "15m") dari environment variable,
+// atau mengembalikan fallback jika kosong atau tidak valid
+func GetDuration(key string, fallback time.Duration) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(key)); err == nil && d > 0 {
+		return d
+	}
+	return fallback
+}
+
+// insecureJWTSecrets berisi secret bawaan dan contoh dari README/.env
+var insecureJWTSecrets = []string{
+	"",
+	"your-secret-key",
+	"my-super-secret-jwt-token-for-ecommerce-app",
+}
+
+// checkJWTSecret menolak secret HS256 bawaan atau terlalu pendek saat GO_ENV=production
+func checkJWTSecret() error {
+	if os.Getenv("GO_ENV") != "production" {
+		return nil
+	}
+
+	alg := strings.ToUpper(os.Getenv("JWT_SIGNING_ALG"))
+	if alg != "" && alg != "HS256" {
+		return nil
+	}
+
+	secret := os.Getenv("JWT_SECRET")
+	for _, insecure := range insecureJWTSecrets {
+		if secret == insecure {
+			return errors.New("JWT_SECRET kosong atau masih memakai nilai bawaan")
+		}
+	}
+	if len(secret) < 32 {
+		return errors.New("JWT_SECRET minimal 32 karakter")
+	}
+	return nil
+}
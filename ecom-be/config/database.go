@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
@@ -40,19 +41,117 @@ func ConnectDatabase() {
 	
 	DB = database
 	
-	// Migrate the schema
-	err = DB.AutoMigrate(
+	if err := Migrate(); err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
+	
+	if err := SeedRoles(); err != nil {
+		log.Fatalf("Failed to seed roles: %v", err)
+	}
+	
+	log.Println("Database migration completed")
+}
+
+// Migrate menyesuaikan skema database dengan model
+func Migrate() error {
+	err := DB.AutoMigrate(
 		&models.User{},
+		&models.Permission{},
+		&models.Role{},
+		&models.Category{},
 		&models.Product{},
+		&models.ProductOption{},
+		&models.ProductVariant{},
+		&models.VariantOption{},
+		&models.ProductImage{},
 		&models.Cart{},
 		&models.CartItem{},
 		&models.Order{},
 		&models.OrderItem{},
+		&models.RefreshToken{},
+		&models.RevokedToken{},
+		&models.PasswordResetToken{},
+		&models.EmailVerificationToken{},
+		&models.RecoveryCode{},
+		&models.LoginAttempt{},
+		&models.LoginThrottle{},
+		&models.UserIdentity{},
+		&models.Address{},
+		&models.WishlistItem{},
+		&models.Device{},
+		&models.Session{},
+		&models.APIKey{},
+		&models.AppPlatform{},
+		&models.AppMessage{},
+		&models.FeatureFlag{},
+		&models.DataMigration{},
 	)
-	
 	if err != nil {
-		log.Fatalf("Failed to migrate database: %v", err)
+		return err
 	}
-	
-	log.Println("Database migration completed")
+
+	// Keranjang dan pesanan tamu tidak memiliki user_id. AutoMigrate tidak
+	// menghapus NOT NULL dari kolom lama, jadi kolomnya diubah manual.
+	for _, model := range []interface{}{&models.Cart{}, &models.Order{}} {
+		if err := allowNull(model, "UserID", "user_id"); err != nil {
+			return err
+		}
+	}
+
+	return runDataMigration("backfill_email_verified_at", backfillEmailVerification)
+}
+
+// runDataMigration menjalankan migrasi data satu kali; nama yang sudah tercatat dilewati
+func runDataMigration(name string, migrate func(tx *gorm.DB) error) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.DataMigration{}).Where("name = ?", name).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+		if err := migrate(tx); err != nil {
+			return err
+		}
+		return tx.Create(&models.DataMigration{Name: name, AppliedAt: time.Now()}).Error
+	})
+}
+
+// backfillEmailVerification menandai akun yang dibuat sebelum verifikasi email
+// diwajibkan sebagai sudah terverifikasi, agar pelanggan lama tetap bisa checkout.
+// Batasnya adalah token verifikasi pertama, karena setiap akun baru sejak fitur
+// itu ada langsung mendapat token saat registrasi.
+func backfillEmailVerification(tx *gorm.DB) error {
+	var first models.EmailVerificationToken
+	cutover := time.Now()
+	err := tx.Order("created_at").Limit(1).Find(&first).Error
+	if err != nil {
+		return err
+	}
+	if first.ID != 0 {
+		cutover = first.CreatedAt
+	}
+
+	return tx.Model(&models.User{}).
+		Where("email_verified_at IS NULL AND created_at < ?", cutover).
+		Update("email_verified_at", gorm.Expr("created_at")).Error
+}
+
+// allowNull mengubah kolom menjadi nullable jika di database masih NOT NULL
+func allowNull(model interface{}, field, column string) error {
+	columns, err := DB.Migrator().ColumnTypes(model)
+	if err != nil {
+		return err
+	}
+
+	for _, col := range columns {
+		if col.Name() != column {
+			continue
+		}
+		if nullable, ok := col.Nullable(); ok && !nullable {
+			return DB.Migrator().AlterColumn(model, field)
+		}
+	}
+	return nil
 }
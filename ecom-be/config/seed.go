@@ -0,0 +1,55 @@
+package config
+
+import (
+	"ecom-be/models"
+
+	"gorm.io/gorm"
+)
+
+// SeedRoles memastikan permission dan role bawaan tersedia.
+// Permission baru selalu ditambahkan ke role admin; permission role lain
+// hanya diisi saat role pertama kali dibuat agar perubahan admin tidak tertimpa.
+func SeedRoles() error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		permissions := make(map[string]models.Permission, len(models.DefaultPermissions))
+		for _, p := range models.DefaultPermissions {
+			permission := p
+			if err := tx.Where(models.Permission{Name: p.Name}).
+				Attrs(models.Permission{Description: p.Description}).
+				FirstOrCreate(&permission).Error; err != nil {
+				return err
+			}
+			permissions[permission.Name] = permission
+		}
+
+		for name, names := range models.DefaultRolePermissions {
+			var role models.Role
+			result := tx.Where("name = ?", name).Limit(1).Find(&role)
+			if result.Error != nil {
+				return result.Error
+			}
+
+			if result.RowsAffected == 0 {
+				role = models.Role{Name: name}
+				for _, n := range names {
+					role.Permissions = append(role.Permissions, permissions[n])
+				}
+				if err := tx.Create(&role).Error; err != nil {
+					return err
+				}
+			}
+
+			if name == models.RoleAdmin {
+				all := make([]models.Permission, 0, len(permissions))
+				for _, p := range permissions {
+					all = append(all, p)
+				}
+				if err := tx.Model(&role).Association("Permissions").Append(all); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
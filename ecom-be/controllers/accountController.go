@@ -0,0 +1,335 @@
+package controllers
+
+import (
+	"archive/zip"
+	"bytes"
+	"ecom-be/config"
+	"ecom-be/middleware"
+	"ecom-be/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// scrubbedAddress menggantikan alamat pengiriman pada pesanan milik akun yang dihapus
+const scrubbedAddress = "[dihapus]"
+
+var errActiveOrders = errors.New("masih ada pesanan yang belum selesai")
+
+// ExportAccount mengunduh arsip ZIP berisi data pribadi user dalam format JSON
+func ExportAccount(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	files, err := accountExportFiles(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data akun"})
+		return
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, file := range files {
+		data, err := json.MarshalIndent(file.data, "", "  ")
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyusun arsip data"})
+			return
+		}
+
+		w, err := archive.Create(file.name)
+		if err == nil {
+			_, err = w.Write(data)
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyusun arsip data"})
+			return
+		}
+	}
+	if err := archive.Close(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyusun arsip data"})
+		return
+	}
+
+	filename := fmt.Sprintf("data-akun-%d-%s.zip", user.ID, time.Now().Format("20060102"))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
+
+type exportFile struct {
+	name string
+	data interface{}
+}
+
+// accountExportFiles mengumpulkan isi arsip export, satu file JSON per jenis data
+func accountExportFiles(user models.User) ([]exportFile, error) {
+	var identities []models.UserIdentity
+	if err := config.DB.Where("user_id = ?", user.ID).Find(&identities).Error; err != nil {
+		return nil, err
+	}
+
+	linked := make([]gin.H, 0, len(identities))
+	for _, identity := range identities {
+		linked = append(linked, gin.H{
+			"provider":  identity.Provider,
+			"email":     identity.Email,
+			"linked_at": identity.CreatedAt,
+		})
+	}
+
+	profile := gin.H{
+		"id":                 user.ID,
+		"name":               user.Name,
+		"email":              user.Email,
+		"phone":              user.Phone,
+		"role":               user.Role,
+		"email_verified_at":  user.EmailVerifiedAt,
+		"two_factor_enabled": user.IsTwoFactorEnabled(),
+		"linked_accounts":    linked,
+		"created_at":         user.CreatedAt,
+	}
+
+	var orders []models.Order
+	if err := config.DB.Preload("OrderItems.Product").Where("user_id = ?", user.ID).Order("created_at").Find(&orders).Error; err != nil {
+		return nil, err
+	}
+
+	orderData := make([]gin.H, 0, len(orders))
+	for _, order := range orders {
+		items := make([]gin.H, 0, len(order.OrderItems))
+		for _, item := range order.OrderItems {
+			items = append(items, gin.H{
+				"product_id":   item.ProductID,
+				"product_name": item.Product.Name,
+				"sku":          item.SKU,
+				"variant":      item.VariantLabel,
+				"quantity":     item.Quantity,
+				"price":        item.Price,
+			})
+		}
+
+		orderData = append(orderData, gin.H{
+			"id":               order.ID,
+			"status":           order.Status,
+			"total_amount":     order.TotalAmount,
+			"shipping_address": order.ShippingAddress,
+			"payment_method":   order.PaymentMethod,
+			"items":            items,
+			"created_at":       order.CreatedAt,
+			"updated_at":       order.UpdatedAt,
+		})
+	}
+
+	cartData := make([]gin.H, 0)
+	var cart models.Cart
+	err := config.DB.Preload("CartItems.Product").Where("user_id = ?", user.ID).First(&cart).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	for _, item := range cart.CartItems {
+		cartData = append(cartData, gin.H{
+			"product_id":   item.ProductID,
+			"product_name": item.Product.Name,
+			"variant_id":   item.VariantID,
+			"quantity":     item.Quantity,
+			"added_at":     item.CreatedAt,
+		})
+	}
+
+	var wishlist []models.WishlistItem
+	if err := config.DB.Preload("Product").Where("user_id = ?", user.ID).Order("created_at").Find(&wishlist).Error; err != nil {
+		return nil, err
+	}
+
+	wishlistData := make([]gin.H, 0, len(wishlist))
+	for _, item := range wishlist {
+		wishlistData = append(wishlistData, gin.H{
+			"product_id":   item.ProductID,
+			"product_name": item.Product.Name,
+			"added_at":     item.CreatedAt,
+		})
+	}
+
+	var addresses []models.Address
+	if err := config.DB.Where("user_id = ?", user.ID).Order("created_at").Find(&addresses).Error; err != nil {
+		return nil, err
+	}
+
+	addressData := make([]gin.H, 0, len(addresses))
+	for _, address := range addresses {
+		addressData = append(addressData, addressResponse(address))
+	}
+
+	var devices []models.Device
+	if err := config.DB.Where("user_id = ?", user.ID).Order("created_at").Find(&devices).Error; err != nil {
+		return nil, err
+	}
+
+	deviceData := make([]gin.H, 0, len(devices))
+	for _, device := range devices {
+		deviceData = append(deviceData, gin.H{
+			"platform":      device.Platform,
+			"registered_at": device.CreatedAt,
+			"last_seen_at":  device.LastSeenAt,
+		})
+	}
+
+	var sessions []models.Session
+	if err := config.DB.Where("user_id = ?", user.ID).Order("created_at").Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+
+	sessionData := make([]gin.H, 0, len(sessions))
+	for _, session := range sessions {
+		sessionData = append(sessionData, gin.H{
+			"user_agent":   session.UserAgent,
+			"ip":           session.IP,
+			"created_at":   session.CreatedAt,
+			"last_seen_at": session.LastSeenAt,
+			"revoked_at":   session.RevokedAt,
+		})
+	}
+
+	return []exportFile{
+		{"profile.json", profile},
+		{"addresses.json", addressData},
+		{"orders.json", orderData},
+		{"cart.json", cartData},
+		{"wishlist.json", wishlistData},
+		{"devices.json", deviceData},
+		{"sessions.json", sessionData},
+	}, nil
+}
+
+// DeleteAccount menghapus akun user. Data pribadi dianonimkan, pesanan tetap
+// disimpan untuk keperluan pembukuan tanpa data pribadi, dan semua token dicabut.
+func DeleteAccount(c *gin.Context) {
+	var input struct {
+		Password string `json:"password" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.Password)); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":  "Password tidak valid",
+			"fields": gin.H{"password": "password salah"},
+		})
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		return anonymizeUser(tx, &user)
+	})
+	if errors.Is(err, errActiveOrders) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Masih ada pesanan yang belum selesai. Batalkan atau tunggu hingga pesanan selesai sebelum menghapus akun"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus akun"})
+		return
+	}
+
+	if err := middleware.RevokeAllUserTokens(user.ID, ""); err != nil {
+		log.Printf("Gagal mencabut token user %d yang dihapus: %v", user.ID, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Akun berhasil dihapus"})
+}
+
+// anonymizeUser mengganti data pribadi user dengan nilai anonim dan menghapus
+// data turunan yang tidak dibutuhkan lagi
+func anonymizeUser(tx *gorm.DB, user *models.User) error {
+	var active int64
+	err := tx.Model(&models.Order{}).
+		Where("user_id = ? AND status IN ?", user.ID, []models.OrderStatus{
+			models.OrderStatusPending,
+			models.OrderStatusProcessing,
+			models.OrderStatusShipped,
+		}).
+		Count(&active).Error
+	if err != nil {
+		return err
+	}
+	if active > 0 {
+		return errActiveOrders
+	}
+
+	email := user.Email
+	now := time.Now()
+	err = tx.Model(user).Updates(map[string]interface{}{
+		"name":                  "Pengguna Terhapus",
+		"email":                 fmt.Sprintf("deleted-%d@deleted.invalid", user.ID),
+		"password":              "",
+		"phone":                 "",
+		"email_verified_at":     nil,
+		"two_factor_secret":     "",
+		"two_factor_enabled_at": nil,
+		"anonymized_at":         now,
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	err = tx.Model(&models.Order{}).Where("user_id = ?", user.ID).Updates(map[string]interface{}{
+		"shipping_address":     scrubbedAddress,
+		"shipping_recipient":   "",
+		"shipping_phone":       "",
+		"shipping_street":      "",
+		"shipping_district":    "",
+		"shipping_city":        "",
+		"shipping_province":    "",
+		"shipping_postal_code": "",
+		"guest_name":           "",
+		"guest_email":          "",
+		"guest_phone":          "",
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	var cart models.Cart
+	if err := tx.Where("user_id = ?", user.ID).First(&cart).Error; err == nil {
+		if err := tx.Where("cart_id = ?", cart.ID).Delete(&models.CartItem{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&cart).Error; err != nil {
+			return err
+		}
+	}
+
+	for _, model := range []interface{}{
+		&models.UserIdentity{},
+		&models.RecoveryCode{},
+		&models.PasswordResetToken{},
+		&models.EmailVerificationToken{},
+		&models.Address{},
+		&models.WishlistItem{},
+		&models.Device{},
+		&models.Session{},
+	} {
+		if err := tx.Where("user_id = ?", user.ID).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+
+	// Riwayat login menyimpan email dan IP, termasuk percobaan gagal yang tidak memiliki user_id
+	return tx.Where("user_id = ? OR email = ?", user.ID, email).Delete(&models.LoginAttempt{}).Error
+}
@@ -0,0 +1,223 @@
+package controllers
+
+import (
+	"ecom-be/config"
+	"ecom-be/middleware"
+	"ecom-be/models"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxAddresses membatasi jumlah alamat tersimpan per user
+const maxAddresses = 20
+
+type addressInput struct {
+	Label      string `json:"label" binding:"max=50"`
+	Recipient  string `json:"recipient" binding:"required,min=2,max=100"`
+	Phone      string `json:"phone" binding:"required,phone"`
+	Street     string `json:"street" binding:"required,max=255"`
+	District   string `json:"district" binding:"required,max=100"`
+	City       string `json:"city" binding:"required,max=100"`
+	Province   string `json:"province" binding:"required,max=100"`
+	PostalCode string `json:"postal_code" binding:"required,numeric,len=5"`
+	IsDefault  bool   `json:"is_default"`
+}
+
+func (in addressInput) fields() models.AddressFields {
+	return models.AddressFields{
+		Recipient:  strings.TrimSpace(in.Recipient),
+		Phone:      in.Phone,
+		Street:     strings.TrimSpace(in.Street),
+		District:   strings.TrimSpace(in.District),
+		City:       strings.TrimSpace(in.City),
+		Province:   strings.TrimSpace(in.Province),
+		PostalCode: in.PostalCode,
+	}
+}
+
+// GetAddresses menampilkan buku alamat user, alamat utama paling atas
+func GetAddresses(c *gin.Context) {
+	userClaims, _ := c.Get("user")
+	claims := userClaims.(*middleware.Claims)
+
+	var addresses []models.Address
+	if err := config.DB.Where("user_id = ?", claims.UserID).Order("is_default DESC, created_at DESC").Find(&addresses).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data alamat"})
+		return
+	}
+
+	result := make([]gin.H, 0, len(addresses))
+	for _, address := range addresses {
+		result = append(result, addressResponse(address))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"addresses": result})
+}
+
+// CreateAddress menambahkan alamat baru. Alamat pertama otomatis menjadi alamat utama.
+func CreateAddress(c *gin.Context) {
+	userClaims, _ := c.Get("user")
+	claims := userClaims.(*middleware.Claims)
+
+	var input addressInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	var count int64
+	config.DB.Model(&models.Address{}).Where("user_id = ?", claims.UserID).Count(&count)
+	if count >= maxAddresses {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Jumlah alamat sudah mencapai batas maksimal"})
+		return
+	}
+
+	address := models.Address{
+		UserID:        claims.UserID,
+		Label:         strings.TrimSpace(input.Label),
+		AddressFields: input.fields(),
+		IsDefault:     input.IsDefault || count == 0,
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if address.IsDefault {
+			if err := clearDefaultAddress(tx, claims.UserID); err != nil {
+				return err
+			}
+		}
+		return tx.Create(&address).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan alamat"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Alamat berhasil ditambahkan",
+		"address": addressResponse(address),
+	})
+}
+
+// UpdateAddress mengubah alamat tersimpan. Pesanan lama tidak ikut berubah
+// karena pesanan menyimpan salinan alamatnya sendiri.
+func UpdateAddress(c *gin.Context) {
+	userClaims, _ := c.Get("user")
+	claims := userClaims.(*middleware.Claims)
+
+	address, ok := findAddressParam(c, claims.UserID)
+	if !ok {
+		return
+	}
+
+	var input addressInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	address.Label = strings.TrimSpace(input.Label)
+	address.AddressFields = input.fields()
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// Alamat utama hanya bisa dipindahkan dengan menjadikan alamat lain sebagai utama
+		if input.IsDefault && !address.IsDefault {
+			if err := clearDefaultAddress(tx, claims.UserID); err != nil {
+				return err
+			}
+			address.IsDefault = true
+		}
+		return tx.Save(&address).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengubah alamat"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Alamat berhasil diubah",
+		"address": addressResponse(address),
+	})
+}
+
+// DeleteAddress menghapus alamat. Jika alamat utama dihapus, alamat terbaru
+// yang tersisa menjadi alamat utama.
+func DeleteAddress(c *gin.Context) {
+	userClaims, _ := c.Get("user")
+	claims := userClaims.(*middleware.Claims)
+
+	address, ok := findAddressParam(c, claims.UserID)
+	if !ok {
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&address).Error; err != nil {
+			return err
+		}
+		if !address.IsDefault {
+			return nil
+		}
+
+		var next models.Address
+		err := tx.Where("user_id = ?", claims.UserID).Order("created_at DESC").First(&next).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return tx.Model(&next).Update("is_default", true).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus alamat"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Alamat berhasil dihapus"})
+}
+
+func clearDefaultAddress(tx *gorm.DB, userID uint) error {
+	return tx.Model(&models.Address{}).
+		Where("user_id = ? AND is_default = ?", userID, true).
+		Update("is_default", false).Error
+}
+
+// findAddressParam mengambil alamat milik user dari parameter :id, atau menulis respons error
+func findAddressParam(c *gin.Context, userID uint) (models.Address, bool) {
+	var address models.Address
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID alamat tidak valid"})
+		return address, false
+	}
+
+	if err := config.DB.Where("id = ? AND user_id = ?", id, userID).First(&address).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Alamat tidak ditemukan"})
+		return address, false
+	}
+
+	return address, true
+}
+
+func addressResponse(address models.Address) gin.H {
+	return gin.H{
+		"id":          address.ID,
+		"label":       address.Label,
+		"recipient":   address.Recipient,
+		"phone":       address.Phone,
+		"street":      address.Street,
+		"district":    address.District,
+		"city":        address.City,
+		"province":    address.Province,
+		"postal_code": address.PostalCode,
+		"is_default":  address.IsDefault,
+		"created_at":  address.CreatedAt,
+		"updated_at":  address.UpdatedAt,
+	}
+}
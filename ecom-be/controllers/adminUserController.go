@@ -0,0 +1,324 @@
+package controllers
+
+import (
+	"ecom-be/config"
+	"ecom-be/middleware"
+	"ecom-be/models"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminListUsers menampilkan daftar user dengan pencarian, filter dan paginasi (users:read)
+func AdminListUsers(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	offset := (page - 1) * limit
+
+	query := config.DB.Model(&models.User{})
+
+	// Cari berdasarkan nama, email atau nomor telepon
+	if search := c.Query("search"); search != "" {
+		like := "%" + search + "%"
+		query = query.Where("name LIKE ? OR email LIKE ? OR phone LIKE ?", like, like, like)
+	}
+
+	if role := c.Query("role"); role != "" {
+		query = query.Where("role = ?", role)
+	}
+
+	switch c.Query("status") {
+	case "active":
+		query = query.Where("suspended_at IS NULL")
+	case "suspended":
+		query = query.Where("suspended_at IS NOT NULL")
+	case "unverified":
+		query = query.Where("email_verified_at IS NULL")
+	}
+
+	// Hitung total sesuai filter
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data user"})
+		return
+	}
+
+	var users []models.User
+	if err := query.Order("created_at DESC").Offset(offset).Limit(limit).Find(&users).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"users": users,
+		"meta": gin.H{
+			"page":     page,
+			"limit":    limit,
+			"total":    total,
+			"lastPage": (int(total) + limit - 1) / limit,
+		},
+	})
+}
+
+// AdminGetUser menampilkan detail user beserta ringkasan pesanannya (users:read)
+func AdminGetUser(c *gin.Context) {
+	user, ok := findUserParam(c)
+	if !ok {
+		return
+	}
+
+	// Ringkasan jumlah pesanan per status
+	var statusRows []struct {
+		Status models.OrderStatus
+		Count  int64
+		Amount float64
+	}
+	config.DB.Model(&models.Order{}).
+		Select("status, COUNT(*) AS count, COALESCE(SUM(total_amount), 0) AS amount").
+		Where("user_id = ?", user.ID).
+		Group("status").
+		Scan(&statusRows)
+
+	var totalOrders int64
+	var totalSpent float64
+	byStatus := gin.H{}
+	for _, row := range statusRows {
+		totalOrders += row.Count
+		byStatus[string(row.Status)] = row.Count
+		if row.Status != models.OrderStatusCancelled {
+			totalSpent += row.Amount
+		}
+	}
+
+	var recentOrders []models.Order
+	config.DB.Where("user_id = ?", user.ID).Order("created_at DESC").Limit(5).Find(&recentOrders)
+
+	c.JSON(http.StatusOK, gin.H{
+		"user": user,
+		"orders": gin.H{
+			"total":      totalOrders,
+			"totalSpent": totalSpent,
+			"byStatus":   byStatus,
+			"recent":     recentOrders,
+		},
+	})
+}
+
+// AdminUpdateUserRole mengubah role user (users:write). Pemanggil harus memiliki
+// semua permission role lama maupun role baru user, agar tidak bisa menaikkan
+// akun lain melebihi haknya sendiri atau menurunkan akun yang lebih tinggi.
+func AdminUpdateUserRole(c *gin.Context) {
+	var input struct {
+		Role string `json:"role" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	user, ok := findUserParam(c)
+	if !ok {
+		return
+	}
+
+	if isCurrentUser(c, user.ID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Tidak dapat mengubah role akun sendiri"})
+		return
+	}
+
+	// Role harus terdaftar di tabel roles
+	var count int64
+	if err := config.DB.Model(&models.Role{}).Where("name = ?", input.Role).Count(&count).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data role"})
+		return
+	}
+	if count == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Data yang dikirim tidak valid",
+			"fields": gin.H{"role": "role tidak dikenal"},
+		})
+		return
+	}
+
+	if !requireRolePermissions(c, user.Role, input.Role) {
+		return
+	}
+
+	if err := config.DB.Model(&user).Update("role", input.Role).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengubah role user"})
+		return
+	}
+
+	// Role tersimpan di token, jadi user harus login ulang agar role baru berlaku
+	if err := middleware.RevokeAllUserTokens(user.ID, ""); err != nil {
+		log.Printf("Gagal mencabut sesi user %d setelah perubahan role: %v", user.ID, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Role user berhasil diubah",
+		"user":    user,
+	})
+}
+
+// AdminSuspendUser menonaktifkan akun user dan mencabut semua sesinya (users:write).
+// Pemanggil harus memiliki semua permission role user tersebut.
+func AdminSuspendUser(c *gin.Context) {
+	var input struct {
+		Reason string `json:"reason" binding:"max=255"`
+	}
+
+	// Body bersifat opsional
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+	}
+
+	user, ok := findUserParam(c)
+	if !ok {
+		return
+	}
+
+	if isCurrentUser(c, user.ID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Tidak dapat menonaktifkan akun sendiri"})
+		return
+	}
+
+	if user.IsSuspended() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Akun user sudah dinonaktifkan"})
+		return
+	}
+
+	if !requireRolePermissions(c, user.Role) {
+		return
+	}
+
+	now := time.Now()
+	if err := config.DB.Model(&user).Updates(map[string]interface{}{
+		"suspended_at":      now,
+		"suspension_reason": input.Reason,
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menonaktifkan akun user"})
+		return
+	}
+
+	if err := middleware.RevokeAllUserTokens(user.ID, ""); err != nil {
+		log.Printf("Gagal mencabut sesi user %d setelah dinonaktifkan: %v", user.ID, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Akun user berhasil dinonaktifkan",
+		"user":    user,
+	})
+}
+
+// AdminReactivateUser mengaktifkan kembali akun user yang dinonaktifkan (users:write).
+// Pemanggil harus memiliki semua permission role user tersebut.
+func AdminReactivateUser(c *gin.Context) {
+	user, ok := findUserParam(c)
+	if !ok {
+		return
+	}
+
+	if !user.IsSuspended() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Akun user tidak sedang dinonaktifkan"})
+		return
+	}
+
+	if !requireRolePermissions(c, user.Role) {
+		return
+	}
+
+	if err := config.DB.Model(&user).Updates(map[string]interface{}{
+		"suspended_at":      nil,
+		"suspension_reason": "",
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengaktifkan akun user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Akun user berhasil diaktifkan kembali",
+		"user":    user,
+	})
+}
+
+// findUserParam mengambil user berdasarkan parameter :id dan menulis respons error jika gagal
+func findUserParam(c *gin.Context) (models.User, bool) {
+	var user models.User
+
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID user tidak valid"})
+		return user, false
+	}
+
+	if err := config.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User tidak ditemukan"})
+		return user, false
+	}
+
+	return user, true
+}
+
+// requireRolePermissions memastikan pemanggil memiliki semua permission setiap
+// role, atau menulis respons 403. Request dari API key memakai scope kuncinya.
+func requireRolePermissions(c *gin.Context, roles ...string) bool {
+	var granted map[string]bool
+	if value, exists := c.Get("api_key"); exists {
+		key, ok := value.(*middleware.APIKeyPrincipal)
+		if !ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Akses ditolak"})
+			return false
+		}
+		granted = key.Scopes
+	} else {
+		userClaims, _ := c.Get("user")
+		claims, ok := userClaims.(*middleware.Claims)
+		if !ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Akses ditolak"})
+			return false
+		}
+		var err error
+		if granted, err = middleware.RolePermissions(claims.Role); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data permission"})
+			return false
+		}
+	}
+
+	for _, role := range roles {
+		permissions, err := middleware.RolePermissions(role)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data permission"})
+			return false
+		}
+		for permission := range permissions {
+			if !granted[permission] {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Anda tidak memiliki permission " + permission + " milik role " + role})
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isCurrentUser mengecek apakah id adalah user yang sedang login
+func isCurrentUser(c *gin.Context, id uint) bool {
+	userClaims, exists := c.Get("user")
+	if !exists {
+		return false
+	}
+	claims, ok := userClaims.(*middleware.Claims)
+	return ok && claims.UserID == id
+}
@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"ecom-be/config"
+	"ecom-be/middleware"
+	"ecom-be/models"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminListAPIKeys menampilkan semua API key beserta scope dan waktu pemakaian terakhir
+func AdminListAPIKeys(c *gin.Context) {
+	var keys []models.APIKey
+	if err := config.DB.Preload("Scopes").Preload("CreatedBy").Order("created_at DESC").Find(&keys).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data API key"})
+		return
+	}
+
+	result := make([]gin.H, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, apiKeyResponse(key))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"api_keys": result})
+}
+
+// AdminCreateAPIKey membuat API key baru. Kunci hanya ditampilkan sekali di respons ini.
+func AdminCreateAPIKey(c *gin.Context) {
+	userClaims, _ := c.Get("user")
+	claims := userClaims.(*middleware.Claims)
+
+	var input struct {
+		Name      string     `json:"name" binding:"required,min=2,max=100"`
+		Scopes    []string   `json:"scopes" binding:"required,min=1,dive,required"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Data yang dikirim tidak valid",
+			"fields": gin.H{"expires_at": "harus di masa depan"},
+		})
+		return
+	}
+
+	// Admin hanya bisa memberikan scope yang dimilikinya sendiri
+	granted, err := middleware.RolePermissions(claims.Role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data permission"})
+		return
+	}
+	for _, scope := range input.Scopes {
+		if models.NonDelegableScopes[scope] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Scope tidak boleh diberikan ke API key: " + scope})
+			return
+		}
+		if !granted[scope] {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Anda tidak memiliki permission " + scope})
+			return
+		}
+	}
+
+	scopes, ok := findPermissions(c, input.Scopes)
+	if !ok {
+		return
+	}
+
+	raw, prefix, err := middleware.GenerateAPIKey()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat API key"})
+		return
+	}
+
+	key := models.APIKey{
+		Name:        input.Name,
+		Prefix:      prefix,
+		KeyHash:     middleware.HashToken(raw),
+		Scopes:      scopes,
+		CreatedByID: claims.UserID,
+		ExpiresAt:   input.ExpiresAt,
+	}
+	if err := config.DB.Create(&key).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat API key"})
+		return
+	}
+	config.DB.Preload("CreatedBy").First(&key, key.ID)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "API key berhasil dibuat. Simpan kunci ini, kunci tidak akan ditampilkan lagi",
+		"key":     raw,
+		"api_key": apiKeyResponse(key),
+	})
+}
+
+// AdminRevokeAPIKey mencabut API key sehingga tidak bisa dipakai lagi
+func AdminRevokeAPIKey(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID API key tidak valid"})
+		return
+	}
+
+	var key models.APIKey
+	if err := config.DB.First(&key, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key tidak ditemukan"})
+		return
+	}
+
+	if key.RevokedAt != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "API key sudah dicabut"})
+		return
+	}
+
+	if err := config.DB.Model(&key).Update("revoked_at", time.Now()).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mencabut API key"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API key berhasil dicabut"})
+}
+
+func apiKeyResponse(key models.APIKey) gin.H {
+	scopes := make([]string, 0, len(key.Scopes))
+	for _, scope := range key.Scopes {
+		scopes = append(scopes, scope.Name)
+	}
+
+	return gin.H{
+		"id":     key.ID,
+		"name":   key.Name,
+		"prefix": key.Prefix,
+		"scopes": scopes,
+		"created_by": gin.H{
+			"id":    key.CreatedBy.ID,
+			"email": key.CreatedBy.Email,
+		},
+		"expires_at":   key.ExpiresAt,
+		"last_used_at": key.LastUsedAt,
+		"last_used_ip": key.LastUsedIP,
+		"revoked_at":   key.RevokedAt,
+		"active":       key.IsActive(),
+		"created_at":   key.CreatedAt,
+	}
+}
@@ -0,0 +1,359 @@
+package controllers
+
+import (
+	"ecom-be/config"
+	"ecom-be/middleware"
+	"ecom-be/models"
+	"ecom-be/push"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetAppConfig mengembalikan konfigurasi untuk aplikasi mobile: versi yang didukung,
+// pengumuman yang sedang berlaku, dan feature toggle. Endpoint ini tidak terkena
+// pemeriksaan versi minimum agar build lama tetap bisa menampilkan pesan update.
+func GetAppConfig(c *gin.Context) {
+	platforms, err := middleware.AppPlatforms()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil konfigurasi aplikasi"})
+		return
+	}
+
+	var messages []models.AppMessage
+	if err := config.DB.Order("created_at DESC").Find(&messages).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil konfigurasi aplikasi"})
+		return
+	}
+
+	var flags []models.FeatureFlag
+	if err := config.DB.Find(&flags).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil konfigurasi aplikasi"})
+		return
+	}
+
+	platform := strings.ToLower(c.GetHeader(middleware.AppPlatformHeader))
+	if platform == "" {
+		platform = strings.ToLower(c.Query("platform"))
+	}
+
+	platformData := gin.H{}
+	for name, p := range platforms {
+		platformData[name] = appPlatformResponse(p)
+	}
+
+	now := time.Now()
+	messageData := make([]gin.H, 0, len(messages))
+	for _, m := range messages {
+		if !m.IsActiveAt(now) || (m.Platform != "" && platform != "" && m.Platform != platform) {
+			continue
+		}
+		messageData = append(messageData, appMessageResponse(m))
+	}
+
+	features := make(map[string]bool, len(flags))
+	for _, flag := range flags {
+		features[flag.Key] = flag.Enabled
+	}
+
+	response := gin.H{
+		"platforms": platformData,
+		"messages":  messageData,
+		"features":  features,
+	}
+
+	// Jika aplikasi mengirim versinya, sertakan status update untuk build tersebut
+	if p, ok := platforms[platform]; ok {
+		version := c.GetHeader(middleware.AppVersionHeader)
+		if cmpMin, err := middleware.CompareAppVersions(version, p.MinVersion); err == nil {
+			cmpLatest, _ := middleware.CompareAppVersions(version, p.LatestVersion)
+			response["update_required"] = cmpMin < 0
+			response["update_available"] = cmpLatest < 0
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// AdminGetAppConfig menampilkan semua konfigurasi aplikasi, termasuk pengumuman yang belum/tidak berlaku
+func AdminGetAppConfig(c *gin.Context) {
+	var platforms []models.AppPlatform
+	var messages []models.AppMessage
+	var flags []models.FeatureFlag
+
+	if err := config.DB.Order("platform").Find(&platforms).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil konfigurasi aplikasi"})
+		return
+	}
+	if err := config.DB.Order("created_at DESC").Find(&messages).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil konfigurasi aplikasi"})
+		return
+	}
+	if err := config.DB.Order("`key`").Find(&flags).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil konfigurasi aplikasi"})
+		return
+	}
+
+	platformData := make([]gin.H, 0, len(platforms))
+	for _, p := range platforms {
+		data := appPlatformResponse(p)
+		data["platform"] = p.Platform
+		platformData = append(platformData, data)
+	}
+
+	now := time.Now()
+	messageData := make([]gin.H, 0, len(messages))
+	for _, m := range messages {
+		data := appMessageResponse(m)
+		data["platform"] = m.Platform
+		data["active"] = m.IsActiveAt(now)
+		messageData = append(messageData, data)
+	}
+
+	flagData := make([]gin.H, 0, len(flags))
+	for _, flag := range flags {
+		flagData = append(flagData, gin.H{
+			"key":         flag.Key,
+			"enabled":     flag.Enabled,
+			"description": flag.Description,
+			"updated_at":  flag.UpdatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"platforms": platformData,
+		"messages":  messageData,
+		"features":  flagData,
+	})
+}
+
+// AdminUpdateAppPlatform mengatur versi minimum dan terbaru untuk satu platform
+func AdminUpdateAppPlatform(c *gin.Context) {
+	platform := strings.ToLower(c.Param("platform"))
+	if platform != push.PlatformAndroid && platform != push.PlatformIOS {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Platform harus android atau ios"})
+		return
+	}
+
+	var input struct {
+		MinVersion    string `json:"min_version" binding:"required,appversion"`
+		LatestVersion string `json:"latest_version" binding:"required,appversion"`
+		StoreURL      string `json:"store_url" binding:"omitempty,url,max=255"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	if cmp, _ := middleware.CompareAppVersions(input.MinVersion, input.LatestVersion); cmp > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Data yang dikirim tidak valid",
+			"fields": gin.H{"min_version": "tidak boleh lebih baru dari latest_version"},
+		})
+		return
+	}
+
+	row := models.AppPlatform{
+		Platform:      platform,
+		MinVersion:    input.MinVersion,
+		LatestVersion: input.LatestVersion,
+		StoreURL:      input.StoreURL,
+	}
+	if err := config.DB.Save(&row).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan versi aplikasi"})
+		return
+	}
+
+	middleware.InvalidateAppConfigCache()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Versi aplikasi berhasil disimpan",
+		"platform": appPlatformResponse(row),
+	})
+}
+
+type appMessageInput struct {
+	Title    string     `json:"title" binding:"required,max=100"`
+	Body     string     `json:"body"`
+	Level    string     `json:"level" binding:"omitempty,oneof=info warning maintenance"`
+	Platform string     `json:"platform" binding:"omitempty,oneof=android ios"`
+	StartsAt *time.Time `json:"starts_at"`
+	EndsAt   *time.Time `json:"ends_at"`
+}
+
+// apply menyalin input ke pesan, atau menulis respons error jika rentang waktunya tidak valid
+func (in appMessageInput) apply(c *gin.Context, m *models.AppMessage) bool {
+	if in.StartsAt != nil && in.EndsAt != nil && !in.EndsAt.After(*in.StartsAt) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Data yang dikirim tidak valid",
+			"fields": gin.H{"ends_at": "harus setelah starts_at"},
+		})
+		return false
+	}
+
+	m.Title = in.Title
+	m.Body = in.Body
+	m.Level = in.Level
+	if m.Level == "" {
+		m.Level = models.AppMessageInfo
+	}
+	m.Platform = in.Platform
+	m.StartsAt = in.StartsAt
+	m.EndsAt = in.EndsAt
+	return true
+}
+
+// AdminCreateAppMessage membuat pengumuman baru untuk aplikasi
+func AdminCreateAppMessage(c *gin.Context) {
+	var input appMessageInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	var message models.AppMessage
+	if !input.apply(c, &message) {
+		return
+	}
+
+	if err := config.DB.Create(&message).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan pengumuman"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":     "Pengumuman berhasil dibuat",
+		"app_message": appMessageResponse(message),
+	})
+}
+
+// AdminUpdateAppMessage mengubah pengumuman
+func AdminUpdateAppMessage(c *gin.Context) {
+	message, ok := findAppMessageParam(c)
+	if !ok {
+		return
+	}
+
+	var input appMessageInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	if !input.apply(c, &message) {
+		return
+	}
+
+	if err := config.DB.Save(&message).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan pengumuman"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Pengumuman berhasil diubah",
+		"app_message": appMessageResponse(message),
+	})
+}
+
+// AdminDeleteAppMessage menghapus pengumuman
+func AdminDeleteAppMessage(c *gin.Context) {
+	message, ok := findAppMessageParam(c)
+	if !ok {
+		return
+	}
+
+	if err := config.DB.Delete(&message).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus pengumuman"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Pengumuman berhasil dihapus"})
+}
+
+// AdminUpdateFeatureFlag membuat atau mengubah feature toggle
+func AdminUpdateFeatureFlag(c *gin.Context) {
+	key := c.Param("key")
+	if len(key) > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Key fitur maksimal 100 karakter"})
+		return
+	}
+
+	var input struct {
+		Enabled     *bool  `json:"enabled" binding:"required"`
+		Description string `json:"description" binding:"max=255"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	flag := models.FeatureFlag{
+		Key:         key,
+		Enabled:     *input.Enabled,
+		Description: input.Description,
+	}
+	if err := config.DB.Save(&flag).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan fitur"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Fitur berhasil disimpan"})
+}
+
+// AdminDeleteFeatureFlag menghapus feature toggle
+func AdminDeleteFeatureFlag(c *gin.Context) {
+	result := config.DB.Where("`key` = ?", c.Param("key")).Delete(&models.FeatureFlag{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus fitur"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Fitur tidak ditemukan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Fitur berhasil dihapus"})
+}
+
+// findAppMessageParam mengambil pengumuman dari parameter :id, atau menulis respons error
+func findAppMessageParam(c *gin.Context) (models.AppMessage, bool) {
+	var message models.AppMessage
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID pengumuman tidak valid"})
+		return message, false
+	}
+
+	if err := config.DB.First(&message, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pengumuman tidak ditemukan"})
+		return message, false
+	}
+
+	return message, true
+}
+
+func appPlatformResponse(p models.AppPlatform) gin.H {
+	return gin.H{
+		"min_version":    p.MinVersion,
+		"latest_version": p.LatestVersion,
+		"store_url":      p.StoreURL,
+		"updated_at":     p.UpdatedAt,
+	}
+}
+
+func appMessageResponse(m models.AppMessage) gin.H {
+	return gin.H{
+		"id":        m.ID,
+		"title":     m.Title,
+		"body":      m.Body,
+		"level":     m.Level,
+		"starts_at": m.StartsAt,
+		"ends_at":   m.EndsAt,
+	}
+}
@@ -0,0 +1,138 @@
+package controllers
+
+import (
+	"ecom-be/middleware"
+	"ecom-be/models"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RefreshToken menukar refresh token dengan access token dan refresh token baru
+func RefreshToken(c *gin.Context) {
+	var input struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	pair, err := middleware.RotateRefreshToken(input.RefreshToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, middleware.ErrRefreshTokenReused):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token sudah pernah digunakan, silakan login kembali"})
+		case errors.Is(err, middleware.ErrRefreshTokenExpired):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token sudah kedaluwarsa, silakan login kembali"})
+		case errors.Is(err, middleware.ErrRefreshTokenInvalid):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token tidak valid"})
+		case errors.Is(err, middleware.ErrAccountSuspended):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Akun Anda telah dinonaktifkan"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Tidak dapat membuat token"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, tokenResponse(pair))
+}
+
+// Logout mencabut access token yang sedang dipakai beserta refresh token-nya
+func Logout(c *gin.Context) {
+	userClaims, _ := c.Get("user")
+	claims := userClaims.(*middleware.Claims)
+
+	if err := middleware.RevokeSession(claims); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal logout"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logout berhasil"})
+}
+
+// LogoutAll mencabut semua token milik user di semua perangkat
+func LogoutAll(c *gin.Context) {
+	userClaims, _ := c.Get("user")
+	claims := userClaims.(*middleware.Claims)
+
+	if err := middleware.RevokeAllUserTokens(claims.UserID, ""); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal logout dari semua perangkat"})
+		return
+	}
+
+	// Token saat ini ikut dicabut meskipun tidak tercatat sebagai refresh token
+	if err := middleware.RevokeToken(claims.ID, claims.UserID, claims.ExpiresAt.Time); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal logout dari semua perangkat"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logout dari semua perangkat berhasil"})
+}
+
+// completeLogin dipanggil setelah kredensial utama terverifikasi. Jika user
+// mengaktifkan 2FA, yang dikirim hanya challenge token untuk POST /login/2fa.
+func completeLogin(c *gin.Context, user models.User) {
+	if user.IsTwoFactorEnabled() {
+		challenge, err := middleware.GenerateTwoFactorChallenge(user.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Tidak dapat membuat token"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"two_factor_required": true,
+			"challenge_token":     challenge,
+			"expires_in":          int64(middleware.TwoFactorChallengeTTL.Seconds()),
+		})
+		return
+	}
+
+	issueLoginTokens(c, user, middleware.TokenOptions{})
+}
+
+// issueLoginTokens menerbitkan access token dan refresh token lalu mengirim respons login
+func issueLoginTokens(c *gin.Context, user models.User, opts middleware.TokenOptions) {
+	opts.UserAgent = truncate(c.Request.UserAgent(), 255)
+	opts.IP = c.ClientIP()
+
+	pair, err := middleware.IssueTokenPair(user, opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Tidak dapat membuat token"})
+		return
+	}
+
+	response := tokenResponse(pair)
+	response["user"] = gin.H{
+		"id":             user.ID,
+		"name":           user.Name,
+		"email":          user.Email,
+		"role":           user.Role,
+		"email_verified": user.IsEmailVerified(),
+	}
+
+	// Role mewajibkan 2FA tetapi user belum mendaftar: aplikasi perlu mengarahkan ke setup 2FA
+	if required, err := middleware.RoleRequiresTwoFactor(user.Role); err == nil && required && !user.IsTwoFactorEnabled() {
+		response["two_factor_setup_required"] = true
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// JWKS mempublikasikan public key penanda tangan JWT agar layanan lain bisa memverifikasi token
+func JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, gin.H{"keys": middleware.PublicJWKs()})
+}
+
+// tokenResponse menyusun payload token yang dikirim ke client
+func tokenResponse(pair *middleware.TokenPair) gin.H {
+	return gin.H{
+		"token":         pair.AccessToken,
+		"refresh_token": pair.RefreshToken,
+		"token_type":    "Bearer",
+		"expires_in":    pair.ExpiresIn(),
+	}
+}
@@ -4,10 +4,19 @@ import (
 	"ecom-be/config"
 	"ecom-be/middleware"
 	"ecom-be/models"
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var (
+	errProductNotFound   = errors.New("produk tidak ditemukan")
+	errVariantNotFound   = errors.New("varian produk tidak ditemukan")
+	errVariantRequired   = errors.New("varian produk wajib dipilih")
+	errInsufficientStock = errors.New("stok produk tidak mencukupi")
 )
 
 // GetCart mengambil cart user yang sedang login
@@ -19,26 +28,31 @@ func GetCart(c *gin.Context) {
 
 	// Cek apakah user memiliki cart
 	var cart models.Cart
-	result := config.DB.Preload("CartItems.Product").Where("user_id = ?", userID).First(&cart)
+	result := config.DB.Preload("CartItems.Product").Preload("CartItems.Variant.Options").Where("user_id = ?", userID).First(&cart)
 	
 	// Jika cart tidak ditemukan, buat cart baru
 	if result.Error != nil {
 		cart = models.Cart{
-			UserID: userID,
+			UserID: &userID,
 		}
 		config.DB.Create(&cart)
 	}
 
+	c.JSON(http.StatusOK, cartResponse(cart))
+}
+
+// cartResponse menyusun isi cart beserta total harganya
+func cartResponse(cart models.Cart) gin.H {
 	// Hitung total harga cart
 	var total float64 = 0
 	for _, item := range cart.CartItems {
-		total += item.Product.Price * float64(item.Quantity)
+		total += item.UnitPrice() * float64(item.Quantity)
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	return gin.H{
 		"cart": cart,
 		"total": total,
-	})
+	}
 }
 
 // AddToCart menambahkan produk ke keranjang
@@ -50,8 +64,9 @@ func AddToCart(c *gin.Context) {
 
 	// Parse input
 	var input struct {
-		ProductID uint `json:"product_id" binding:"required"`
-		Quantity  int  `json:"quantity" binding:"required,min=1"`
+		ProductID uint  `json:"product_id" binding:"required"`
+		VariantID *uint `json:"variant_id"` // wajib untuk produk yang memiliki varian
+		Quantity  int   `json:"quantity" binding:"required,min=1"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -59,55 +74,107 @@ func AddToCart(c *gin.Context) {
 		return
 	}
 
+	if err := addProductToCart(config.DB, userID, input.ProductID, input.VariantID, input.Quantity); err != nil {
+		respondAddToCartError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Produk berhasil ditambahkan ke keranjang"})
+}
+
+// addProductToCart mengecek stok lalu menambahkan produk ke cart user.
+// Dipakai oleh AddToCart dan pemindahan produk dari wishlist.
+func addProductToCart(db *gorm.DB, userID, productID uint, variantID *uint, quantity int) error {
+	// Cari atau buat cart untuk user
+	var cart models.Cart
+	if err := db.Where("user_id = ?", userID).First(&cart).Error; err != nil {
+		cart = models.Cart{UserID: &userID}
+		if err := db.Create(&cart).Error; err != nil {
+			return err
+		}
+	}
+
+	return addItemToCart(db, cart, productID, variantID, quantity)
+}
+
+// addItemToCart mengecek stok lalu menambahkan produk ke cart tertentu (milik user atau tamu)
+func addItemToCart(db *gorm.DB, cart models.Cart, productID uint, variantID *uint, quantity int) error {
 	// Cek ketersediaan produk
 	var product models.Product
-	if err := config.DB.First(&product, input.ProductID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Produk tidak ditemukan"})
-		return
+	if err := db.First(&product, productID).Error; err != nil {
+		return errProductNotFound
+	}
+
+	// Produk bervarian dicek stoknya per varian
+	variant, err := findProductVariant(db, product.ID, variantID)
+	if err != nil {
+		return err
+	}
+	stock := product.Stock
+	if variant != nil {
+		stock = variant.Stock
 	}
 
 	// Cek stok produk
-	if product.Stock < input.Quantity {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Stok produk tidak mencukupi"})
-		return
+	if stock < quantity {
+		return errInsufficientStock
 	}
 
-	// Cari atau buat cart untuk user
-	var cart models.Cart
-	result := config.DB.Where("user_id = ?", userID).First(&cart)
-	if result.Error != nil {
-		cart = models.Cart{UserID: userID}
-		config.DB.Create(&cart)
+	// Cek apakah produk (dengan varian yang sama) sudah ada di cart
+	query := db.Where("cart_id = ? AND product_id = ?", cart.ID, productID)
+	if variant != nil {
+		query = query.Where("variant_id = ?", variant.ID)
+	} else {
+		query = query.Where("variant_id IS NULL")
 	}
 
-	// Cek apakah produk sudah ada di cart
 	var cartItem models.CartItem
-	result = config.DB.Where("cart_id = ? AND product_id = ?", cart.ID, input.ProductID).First(&cartItem)
-	
-	if result.Error != nil {
+	if err := query.First(&cartItem).Error; err != nil {
 		// Produk belum ada di cart, buat cart item baru
 		cartItem = models.CartItem{
 			CartID:    cart.ID,
-			ProductID: input.ProductID,
-			Quantity:  input.Quantity,
+			ProductID: productID,
+			Quantity:  quantity,
 		}
-		config.DB.Create(&cartItem)
-	} else {
-		// Produk sudah ada di cart, update quantity
-		cartItem.Quantity += input.Quantity
-		config.DB.Save(&cartItem)
+		if variant != nil {
+			cartItem.VariantID = &variant.ID
+		}
+		return db.Create(&cartItem).Error
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Produk berhasil ditambahkan ke keranjang"})
+	// Produk sudah ada di cart, update quantity
+	cartItem.Quantity += quantity
+	return db.Save(&cartItem).Error
+}
+
+// respondAddToCartError menulis respons error dari addProductToCart
+func respondAddToCartError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errProductNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Produk tidak ditemukan"})
+	case errors.Is(err, errVariantNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Varian produk tidak ditemukan"})
+	case errors.Is(err, errVariantRequired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Pilih varian produk terlebih dahulu"})
+	case errors.Is(err, errInsufficientStock):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Stok produk tidak mencukupi"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menambahkan produk ke keranjang"})
+	}
 }
 
 // UpdateCartItem mengubah jumlah produk di keranjang
 func UpdateCartItem(c *gin.Context) {
-	// Ambil user ID dari JWT token
-	userClaims, _ := c.Get("user")
-	claims := userClaims.(*middleware.Claims)
-	userID := claims.UserID
+	cart, ok := findUserCart(c)
+	if !ok {
+		return
+	}
 
+	updateCartItem(c, cart)
+}
+
+// updateCartItem mengubah jumlah item dari parameter :id di cart tertentu
+func updateCartItem(c *gin.Context, cart models.Cart) {
 	// Parse input
 	var input struct {
 		Quantity int `json:"quantity" binding:"required,min=1"`
@@ -125,13 +192,6 @@ func UpdateCartItem(c *gin.Context) {
 		return
 	}
 
-	// Cari cart milik user
-	var cart models.Cart
-	if err := config.DB.Where("user_id = ?", userID).First(&cart).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Keranjang tidak ditemukan"})
-		return
-	}
-
 	// Cari cart item
 	var cartItem models.CartItem
 	if err := config.DB.Where("id = ? AND cart_id = ?", cartItemID, cart.ID).First(&cartItem).Error; err != nil {
@@ -146,7 +206,18 @@ func UpdateCartItem(c *gin.Context) {
 		return
 	}
 
-	if product.Stock < input.Quantity {
+	// Produk bervarian dicek stoknya per varian
+	stock := product.Stock
+	if cartItem.VariantID != nil {
+		var variant models.ProductVariant
+		if err := config.DB.First(&variant, *cartItem.VariantID).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Varian produk tidak ditemukan"})
+			return
+		}
+		stock = variant.Stock
+	}
+
+	if stock < input.Quantity {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Stok produk tidak mencukupi"})
 		return
 	}
@@ -160,11 +231,16 @@ func UpdateCartItem(c *gin.Context) {
 
 // RemoveFromCart menghapus produk dari keranjang
 func RemoveFromCart(c *gin.Context) {
-	// Ambil user ID dari JWT token
-	userClaims, _ := c.Get("user")
-	claims := userClaims.(*middleware.Claims)
-	userID := claims.UserID
+	cart, ok := findUserCart(c)
+	if !ok {
+		return
+	}
+
+	removeCartItem(c, cart)
+}
 
+// removeCartItem menghapus item dari parameter :id di cart tertentu
+func removeCartItem(c *gin.Context, cart models.Cart) {
 	// Ambil ID cart item
 	cartItemID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -172,13 +248,6 @@ func RemoveFromCart(c *gin.Context) {
 		return
 	}
 
-	// Cari cart milik user
-	var cart models.Cart
-	if err := config.DB.Where("user_id = ?", userID).First(&cart).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Keranjang tidak ditemukan"})
-		return
-	}
-
 	// Hapus cart item
 	result := config.DB.Where("id = ? AND cart_id = ?", cartItemID, cart.ID).Delete(&models.CartItem{})
 	if result.RowsAffected == 0 {
@@ -191,20 +260,29 @@ func RemoveFromCart(c *gin.Context) {
 
 // ClearCart menghapus semua produk dari keranjang
 func ClearCart(c *gin.Context) {
+	cart, ok := findUserCart(c)
+	if !ok {
+		return
+	}
+
+	// Hapus semua cart item
+	config.DB.Where("cart_id = ?", cart.ID).Delete(&models.CartItem{})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Keranjang berhasil dikosongkan"})
+} 
+
+// findUserCart mengambil cart milik user yang sedang login, atau menulis respons error
+func findUserCart(c *gin.Context) (models.Cart, bool) {
 	// Ambil user ID dari JWT token
 	userClaims, _ := c.Get("user")
 	claims := userClaims.(*middleware.Claims)
-	userID := claims.UserID
 
 	// Cari cart milik user
 	var cart models.Cart
-	if err := config.DB.Where("user_id = ?", userID).First(&cart).Error; err != nil {
+	if err := config.DB.Where("user_id = ?", claims.UserID).First(&cart).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Keranjang tidak ditemukan"})
-		return
+		return cart, false
 	}
 
-	// Hapus semua cart item
-	config.DB.Where("cart_id = ?", cart.ID).Delete(&models.CartItem{})
-
-	c.JSON(http.StatusOK, gin.H{"message": "Keranjang berhasil dikosongkan"})
-} 
\ No newline at end of file
+	return cart, true
+}
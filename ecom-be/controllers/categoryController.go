@@ -0,0 +1,318 @@
+package controllers
+
+import (
+	"ecom-be/config"
+	"ecom-be/models"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var slugInvalidChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+type categoryInput struct {
+	Name      string `json:"name" binding:"required,min=2,max=100"`
+	Slug      string `json:"slug" binding:"max=120"`
+	ParentID  *uint  `json:"parent_id"`
+	Icon      string `json:"icon" binding:"max=255"`
+	SortOrder int    `json:"sort_order"`
+}
+
+// GetCategories menampilkan pohon kategori, diurutkan berdasarkan sort_order lalu nama
+func GetCategories(c *gin.Context) {
+	categories, err := loadCategories()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data kategori"})
+		return
+	}
+
+	children := make(map[uint][]models.Category, len(categories))
+	var roots []models.Category
+	for _, category := range categories {
+		if category.ParentID == nil {
+			roots = append(roots, category)
+		} else {
+			children[*category.ParentID] = append(children[*category.ParentID], category)
+		}
+	}
+
+	var build func([]models.Category) []gin.H
+	build = func(nodes []models.Category) []gin.H {
+		result := make([]gin.H, 0, len(nodes))
+		for _, node := range nodes {
+			data := categoryResponse(node)
+			data["children"] = build(children[node.ID])
+			result = append(result, data)
+		}
+		return result
+	}
+
+	c.JSON(http.StatusOK, gin.H{"categories": build(roots)})
+}
+
+// CreateCategory membuat kategori baru (admin only)
+func CreateCategory(c *gin.Context) {
+	var input categoryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	category := models.Category{}
+	if !input.apply(c, &category) {
+		return
+	}
+
+	if err := config.DB.Create(&category).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat kategori"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Kategori berhasil dibuat",
+		"category": categoryResponse(category),
+	})
+}
+
+// UpdateCategory mengubah kategori, termasuk memindahkannya ke induk lain (admin only)
+func UpdateCategory(c *gin.Context) {
+	category, ok := findCategoryParam(c)
+	if !ok {
+		return
+	}
+
+	var input categoryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	if !input.apply(c, &category) {
+		return
+	}
+
+	if err := config.DB.Save(&category).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengubah kategori"})
+		return
+	}
+
+	// Nama kategori dan induknya ikut diindeks, jadi produk di kategori ini dan turunannya diindeks ulang
+	if categories, err := loadCategories(); err == nil {
+		reindexProducts(categoryProductIDs(models.DescendantIDs(categories, category.ID)))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Kategori berhasil diubah",
+		"category": categoryResponse(category),
+	})
+}
+
+// DeleteCategory menghapus kategori yang tidak memiliki sub-kategori (admin only).
+// Produk di dalamnya tidak ikut terhapus, hanya dilepas dari kategori ini.
+func DeleteCategory(c *gin.Context) {
+	category, ok := findCategoryParam(c)
+	if !ok {
+		return
+	}
+
+	var children int64
+	config.DB.Model(&models.Category{}).Where("parent_id = ?", category.ID).Count(&children)
+	if children > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Kategori masih memiliki sub-kategori"})
+		return
+	}
+
+	productIDs := categoryProductIDs([]uint{category.ID})
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM product_categories WHERE category_id = ?", category.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&category).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus kategori"})
+		return
+	}
+	reindexProducts(productIDs)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Kategori berhasil dihapus"})
+}
+
+// SetProductCategories mengganti daftar kategori sebuah produk (admin only)
+func SetProductCategories(c *gin.Context) {
+	product, ok := findProductParam(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		CategoryIDs []uint `json:"category_ids" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	categories, ok := findCategories(c, input.CategoryIDs)
+	if !ok {
+		return
+	}
+
+	if err := config.DB.Model(&product).Association("Categories").Replace(categories); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan kategori produk"})
+		return
+	}
+	product.Categories = categories
+	reindexProducts([]uint{product.ID})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Kategori produk berhasil disimpan",
+		"product": product,
+	})
+}
+
+// apply menyalin input ke kategori, atau menulis respons error jika slug atau induknya tidak valid
+func (in categoryInput) apply(c *gin.Context, category *models.Category) bool {
+	slug := slugify(in.Slug)
+	if slug == "" {
+		slug = slugify(in.Name)
+	}
+	if slug == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Data yang dikirim tidak valid",
+			"fields": gin.H{"slug": "harus berisi huruf atau angka"},
+		})
+		return false
+	}
+
+	var count int64
+	config.DB.Model(&models.Category{}).Where("slug = ? AND id <> ?", slug, category.ID).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Slug kategori sudah dipakai"})
+		return false
+	}
+
+	if in.ParentID != nil {
+		categories, err := loadCategories()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data kategori"})
+			return false
+		}
+
+		found := false
+		for _, candidate := range categories {
+			if candidate.ID == *in.ParentID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Kategori induk tidak ditemukan"})
+			return false
+		}
+
+		// Kategori tidak boleh dipindahkan ke bawah dirinya sendiri atau turunannya
+		if category.ID != 0 {
+			for _, id := range models.DescendantIDs(categories, category.ID) {
+				if id == *in.ParentID {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "Kategori induk tidak boleh turunan kategori ini"})
+					return false
+				}
+			}
+		}
+	}
+
+	category.ParentID = in.ParentID
+	category.Name = strings.TrimSpace(in.Name)
+	category.Slug = slug
+	category.Icon = strings.TrimSpace(in.Icon)
+	category.SortOrder = in.SortOrder
+	return true
+}
+
+// findCategoryParam mengambil kategori dari parameter :id, atau menulis respons error
+func findCategoryParam(c *gin.Context) (models.Category, bool) {
+	var category models.Category
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID kategori tidak valid"})
+		return category, false
+	}
+
+	if err := config.DB.First(&category, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Kategori tidak ditemukan"})
+		return category, false
+	}
+
+	return category, true
+}
+
+// findCategoryFilter mencari kategori dari ID atau slug dan mengembalikan ID
+// kategori tersebut beserta semua turunannya
+func findCategoryFilter(value string) ([]uint, error) {
+	categories, err := loadCategories()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, category := range categories {
+		if category.Slug == value || strconv.FormatUint(uint64(category.ID), 10) == value {
+			return models.DescendantIDs(categories, category.ID), nil
+		}
+	}
+	return nil, nil
+}
+
+func loadCategories() ([]models.Category, error) {
+	var categories []models.Category
+	err := config.DB.Order("sort_order, name").Find(&categories).Error
+	return categories, err
+}
+
+// slugify mengubah teks menjadi slug huruf kecil yang dipisahkan tanda hubung
+func slugify(s string) string {
+	return strings.Trim(slugInvalidChars.ReplaceAllString(strings.ToLower(s), "-"), "-")
+}
+
+func findCategories(c *gin.Context, ids []uint) ([]models.Category, bool) {
+	categories := []models.Category{}
+	if len(ids) == 0 {
+		return categories, true
+	}
+
+	if err := config.DB.Where("id IN ?", ids).Find(&categories).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data kategori"})
+		return nil, false
+	}
+
+	found := make(map[uint]bool, len(categories))
+	for _, category := range categories {
+		found[category.ID] = true
+	}
+	for _, id := range ids {
+		if !found[id] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Kategori tidak ditemukan: " + strconv.FormatUint(uint64(id), 10)})
+			return nil, false
+		}
+	}
+
+	return categories, true
+}
+
+func categoryResponse(category models.Category) gin.H {
+	return gin.H{
+		"id":         category.ID,
+		"parent_id":  category.ParentID,
+		"name":       category.Name,
+		"slug":       category.Slug,
+		"icon":       category.Icon,
+		"sort_order": category.SortOrder,
+	}
+}
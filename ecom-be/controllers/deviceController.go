@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"context"
+	"ecom-be/config"
+	"ecom-be/middleware"
+	"ecom-be/models"
+	"ecom-be/push"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// pushTimeout membatasi lama pengiriman push notification dalam satu request
+const pushTimeout = 10 * time.Second
+
+// GetDevices menampilkan perangkat user yang terdaftar untuk push notification
+func GetDevices(c *gin.Context) {
+	userClaims, _ := c.Get("user")
+	claims := userClaims.(*middleware.Claims)
+
+	var devices []models.Device
+	if err := config.DB.Where("user_id = ?", claims.UserID).Order("last_seen_at DESC").Find(&devices).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data perangkat"})
+		return
+	}
+
+	result := make([]gin.H, 0, len(devices))
+	for _, device := range devices {
+		result = append(result, gin.H{
+			"id":           device.ID,
+			"platform":     device.Platform,
+			"last_seen_at": device.LastSeenAt,
+			"created_at":   device.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"devices": result})
+}
+
+// RegisterDevice mendaftarkan token FCM/APNs milik user. Token yang sudah
+// terdaftar di akun lain dipindahkan ke user ini.
+func RegisterDevice(c *gin.Context) {
+	userClaims, _ := c.Get("user")
+	claims := userClaims.(*middleware.Claims)
+
+	var input struct {
+		Token    string `json:"token" binding:"required,max=255"`
+		Platform string `json:"platform" binding:"required,oneof=android ios"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	now := time.Now()
+	var device models.Device
+	err := config.DB.Where("token = ?", input.Token).First(&device).Error
+	if err == nil {
+		device.UserID = claims.UserID
+		device.Platform = input.Platform
+		device.LastSeenAt = now
+		err = config.DB.Save(&device).Error
+	} else {
+		device = models.Device{
+			UserID:     claims.UserID,
+			Platform:   input.Platform,
+			Token:      input.Token,
+			LastSeenAt: now,
+		}
+		err = config.DB.Create(&device).Error
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mendaftarkan perangkat"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Perangkat berhasil didaftarkan",
+		"device": gin.H{
+			"id":       device.ID,
+			"platform": device.Platform,
+		},
+	})
+}
+
+// UnregisterDevice menghapus token perangkat, mis. saat user logout dari aplikasi
+func UnregisterDevice(c *gin.Context) {
+	userClaims, _ := c.Get("user")
+	claims := userClaims.(*middleware.Claims)
+
+	var input struct {
+		Token string `json:"token" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	result := config.DB.Where("user_id = ? AND token = ?", claims.UserID, input.Token).Delete(&models.Device{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus perangkat"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Perangkat tidak ditemukan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Perangkat berhasil dihapus"})
+}
+
+// notifyUser mengirim push notification ke semua perangkat user. Kegagalan
+// hanya dicatat di log; token yang dilaporkan tidak berlaku langsung dihapus.
+func notifyUser(userID uint, msg push.Message) {
+	var devices []models.Device
+	if err := config.DB.Where("user_id = ?", userID).Find(&devices).Error; err != nil {
+		log.Printf("Gagal mengambil perangkat user %d: %v", userID, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pushTimeout)
+	defer cancel()
+
+	dead := sendPush(ctx, push.Default, devices, msg)
+	if len(dead) > 0 {
+		if err := config.DB.Delete(&models.Device{}, dead).Error; err != nil {
+			log.Printf("Gagal menghapus token perangkat yang tidak berlaku: %v", err)
+		}
+	}
+}
+
+// sendPush mengirim push notification ke setiap perangkat dan mengembalikan ID
+// perangkat yang token-nya dilaporkan tidak berlaku
+func sendPush(ctx context.Context, sender push.Sender, devices []models.Device, msg push.Message) []uint {
+	var dead []uint
+	for _, device := range devices {
+		err := sender.Send(ctx, push.Device{Platform: device.Platform, Token: device.Token}, msg)
+		switch {
+		case errors.Is(err, push.ErrInvalidToken):
+			dead = append(dead, device.ID)
+		case err != nil:
+			log.Printf("Gagal mengirim push ke perangkat %d: %v", device.ID, err)
+		}
+	}
+	return dead
+}
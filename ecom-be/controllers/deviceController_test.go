@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"context"
+	"ecom-be/models"
+	"ecom-be/push"
+	"slices"
+	"testing"
+)
+
+func TestOrderStatusPush(t *testing.T) {
+	tests := []struct {
+		status models.OrderStatus
+		send   bool
+	}{
+		{models.OrderStatusPending, false},
+		{models.OrderStatusProcessing, false},
+		{models.OrderStatusShipped, true},
+		{models.OrderStatusDelivered, true},
+		{models.OrderStatusCancelled, false},
+	}
+
+	for _, tt := range tests {
+		msg, ok := orderStatusPush(models.Order{ID: 7, Status: tt.status})
+		if ok != tt.send {
+			t.Errorf("status %s: kirim = %v, seharusnya %v", tt.status, ok, tt.send)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if msg.Title == "" || msg.Body == "" {
+			t.Errorf("status %s: judul dan isi push tidak boleh kosong", tt.status)
+		}
+		if msg.Data["order_id"] != "7" || msg.Data["status"] != string(tt.status) {
+			t.Errorf("status %s: data push = %v", tt.status, msg.Data)
+		}
+	}
+}
+
+func TestSendPushReturnsInvalidTokens(t *testing.T) {
+	sender := push.NewFakeSender()
+	sender.MarkInvalid("token-mati")
+
+	devices := []models.Device{
+		{ID: 1, Platform: push.PlatformAndroid, Token: "token-android"},
+		{ID: 2, Platform: push.PlatformIOS, Token: "token-mati"},
+		{ID: 3, Platform: push.PlatformIOS, Token: "token-ios"},
+	}
+	msg, _ := orderStatusPush(models.Order{ID: 7, Status: models.OrderStatusShipped})
+
+	dead := sendPush(context.Background(), sender, devices, msg)
+	if !slices.Equal(dead, []uint{2}) {
+		t.Errorf("perangkat tidak berlaku = %v, seharusnya [2]", dead)
+	}
+
+	sent := sender.Sent()
+	if len(sent) != 2 {
+		t.Fatalf("push terkirim = %d, seharusnya 2", len(sent))
+	}
+	for i, token := range []string{"token-android", "token-ios"} {
+		if sent[i].Device.Token != token || sent[i].Message.Title != msg.Title {
+			t.Errorf("push ke-%d = %+v", i, sent[i])
+		}
+	}
+}
@@ -0,0 +1,362 @@
+package controllers
+
+import (
+	"ecom-be/config"
+	"ecom-be/mailer"
+	"ecom-be/middleware"
+	"ecom-be/models"
+	"ecom-be/throttle"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// guestTokenHeader adalah header tempat aplikasi mengirim token keranjang tamu
+const guestTokenHeader = "X-Guest-Token"
+
+// orderTokenHeader adalah alternatif query ?token= untuk token pelacakan pesanan tamu
+const orderTokenHeader = "X-Order-Token"
+
+// Batas endpoint tamu. Endpoint ini publik, jadi setiap keranjang baru dan
+// setiap checkout dihitung, bukan hanya percobaan yang gagal.
+var (
+	guestCartPolicy          = throttle.Policy{FreeAttempts: 20, BaseDelay: time.Minute, MaxDelay: time.Hour, Window: time.Hour}
+	guestCheckoutIPPolicy    = throttle.Policy{FreeAttempts: 10, BaseDelay: time.Minute, MaxDelay: time.Hour, Window: time.Hour}
+	guestCheckoutEmailPolicy = throttle.Policy{FreeAttempts: 5, BaseDelay: time.Minute, MaxDelay: time.Hour, Window: time.Hour}
+)
+
+// GetGuestCart menampilkan keranjang tamu dari header X-Guest-Token
+func GetGuestCart(c *gin.Context) {
+	cart, ok := findGuestCart(c, true)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, cartResponse(cart))
+}
+
+// AddToGuestCart menambahkan produk ke keranjang tamu. Tanpa header X-Guest-Token,
+// keranjang baru dibuat dan tokennya dikembalikan sebagai guest_token.
+func AddToGuestCart(c *gin.Context) {
+	var input struct {
+		ProductID uint  `json:"product_id" binding:"required"`
+		VariantID *uint `json:"variant_id"`
+		Quantity  int   `json:"quantity" binding:"required,min=1"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	var cart models.Cart
+	var newToken string
+
+	if c.GetHeader(guestTokenHeader) == "" {
+		throttleKeys := map[string]throttle.Policy{"guest_cart_ip:" + c.ClientIP(): guestCartPolicy}
+		if !checkRateLimit(c, throttleKeys, "Terlalu banyak keranjang baru") {
+			return
+		}
+
+		raw, err := middleware.GenerateOpaqueToken()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat keranjang"})
+			return
+		}
+		hash := middleware.HashToken(raw)
+		cart = models.Cart{GuestTokenHash: &hash}
+		if err := config.DB.Create(&cart).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat keranjang"})
+			return
+		}
+		recordThrottleFailure(throttleKeys)
+		newToken = raw
+	} else {
+		var ok bool
+		if cart, ok = findGuestCart(c, false); !ok {
+			return
+		}
+	}
+
+	if err := addItemToCart(config.DB, cart, input.ProductID, input.VariantID, input.Quantity); err != nil {
+		respondAddToCartError(c, err)
+		return
+	}
+
+	response := gin.H{"message": "Produk berhasil ditambahkan ke keranjang"}
+	if newToken != "" {
+		response["guest_token"] = newToken
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateGuestCartItem mengubah jumlah produk di keranjang tamu
+func UpdateGuestCartItem(c *gin.Context) {
+	cart, ok := findGuestCart(c, false)
+	if !ok {
+		return
+	}
+
+	updateCartItem(c, cart)
+}
+
+// RemoveFromGuestCart menghapus produk dari keranjang tamu
+func RemoveFromGuestCart(c *gin.Context) {
+	cart, ok := findGuestCart(c, false)
+	if !ok {
+		return
+	}
+
+	removeCartItem(c, cart)
+}
+
+// GuestCheckout membuat pesanan dari keranjang tamu tanpa akun. Tamu menerima
+// lookup_token untuk melihat status dan membatalkan pesanan. Email yang sudah
+// terdaftar harus checkout lewat akunnya, agar kewajiban verifikasi email
+// sebelum checkout tidak bisa dilewati dengan checkout sebagai tamu.
+func GuestCheckout(c *gin.Context) {
+	var input struct {
+		Name            string `json:"name" binding:"required,min=2,max=100"`
+		Email           string `json:"email" binding:"required,email,max=191"`
+		Phone           string `json:"phone" binding:"required,phone"`
+		ShippingAddress string `json:"shipping_address" binding:"required"`
+		PaymentMethod   string `json:"payment_method" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	email := strings.ToLower(input.Email)
+	throttleKeys := map[string]throttle.Policy{
+		"guest_checkout_ip:" + c.ClientIP(): guestCheckoutIPPolicy,
+		"guest_checkout_email:" + email:     guestCheckoutEmailPolicy,
+	}
+	if !checkRateLimit(c, throttleKeys, "Terlalu banyak checkout") {
+		return
+	}
+
+	var registered int64
+	if err := config.DB.Model(&models.User{}).Where("email = ?", email).Count(&registered).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa email"})
+		return
+	}
+	if registered > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Email sudah terdaftar, silakan login untuk checkout"})
+		return
+	}
+
+	cart, ok := findGuestCart(c, true)
+	if !ok {
+		return
+	}
+
+	order, ok := placeOrder(c, cart, models.Order{
+		GuestName:       strings.TrimSpace(input.Name),
+		GuestEmail:      email,
+		GuestPhone:      input.Phone,
+		ShippingAddress: input.ShippingAddress,
+		PaymentMethod:   input.PaymentMethod,
+	})
+	if !ok {
+		return
+	}
+	recordThrottleFailure(throttleKeys)
+	config.DB.Preload("OrderItems.Product").First(&order, order.ID)
+
+	token, err := middleware.GenerateOrderLookupToken(order.ID)
+	if err != nil {
+		// Pesanan sudah dibuat; tamu tetap bisa melihatnya setelah mendaftar dengan email yang sama
+		log.Printf("Gagal membuat token pelacakan pesanan %d: %v", order.ID, err)
+	} else {
+		sendGuestOrderEmail(order, token)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":      "Pesanan berhasil dibuat",
+		"order":        guestOrderResponse(order),
+		"lookup_token": token,
+	})
+}
+
+// GetGuestOrder menampilkan pesanan tamu berdasarkan token pelacakan
+func GetGuestOrder(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		token = c.GetHeader(orderTokenHeader)
+	}
+
+	order, ok := findLookupOrder(c, token)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, guestOrderResponse(order))
+}
+
+// CancelGuestOrder membatalkan pesanan tamu berdasarkan token pelacakan
+func CancelGuestOrder(c *gin.Context) {
+	var input struct {
+		Token string `json:"token"`
+	}
+
+	// Token boleh dikirim lewat body atau header
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+	}
+	if input.Token == "" {
+		input.Token = c.GetHeader(orderTokenHeader)
+	}
+
+	order, ok := findLookupOrder(c, input.Token)
+	if !ok {
+		return
+	}
+
+	if !cancelOrder(c, order) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Pesanan berhasil dibatalkan"})
+}
+
+// claimGuestOrders memindahkan pesanan tamu dengan email tersebut ke akun user.
+// Hanya dipanggil setelah kepemilikan email terbukti (verifikasi email atau OIDC).
+func claimGuestOrders(tx *gorm.DB, userID uint, email string) error {
+	return tx.Model(&models.Order{}).
+		Where("user_id IS NULL AND guest_email = ?", strings.ToLower(email)).
+		Update("user_id", userID).Error
+}
+
+// StartGuestCartCleanup menghapus keranjang tamu yang tidak diubah selama
+// GUEST_CART_TTL (default 7 hari), beserta isinya, secara berkala
+func StartGuestCartCleanup(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := deleteStaleGuestCarts(time.Now().Add(-config.GetDuration("GUEST_CART_TTL", 7*24*time.Hour))); err != nil {
+				log.Printf("Gagal membersihkan keranjang tamu: %v", err)
+			}
+		}
+	}()
+}
+
+// deleteStaleGuestCarts menghapus keranjang tamu yang keranjang maupun itemnya
+// tidak berubah sejak cutoff
+func deleteStaleGuestCarts(cutoff time.Time) error {
+	var ids []uint
+	err := config.DB.Model(&models.Cart{}).
+		Where("guest_token_hash IS NOT NULL AND updated_at < ?", cutoff).
+		Where("NOT EXISTS (SELECT 1 FROM cart_items WHERE cart_items.cart_id = carts.id AND cart_items.updated_at >= ?)", cutoff).
+		Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("cart_id IN ?", ids).Delete(&models.CartItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Cart{}, ids).Error
+	})
+}
+
+// findGuestCart mengambil keranjang tamu dari header X-Guest-Token, atau menulis respons error
+func findGuestCart(c *gin.Context, withItems bool) (models.Cart, bool) {
+	var cart models.Cart
+
+	raw := c.GetHeader(guestTokenHeader)
+	if raw == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Header " + guestTokenHeader + " wajib diisi"})
+		return cart, false
+	}
+
+	query := config.DB
+	if withItems {
+		query = query.Preload("CartItems.Product").Preload("CartItems.Variant.Options")
+	}
+	if err := query.Where("guest_token_hash = ?", middleware.HashToken(raw)).First(&cart).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Keranjang tidak ditemukan"})
+		return cart, false
+	}
+
+	return cart, true
+}
+
+// findLookupOrder mengambil pesanan dari token pelacakan, atau menulis respons error
+func findLookupOrder(c *gin.Context, token string) (models.Order, bool) {
+	var order models.Order
+
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token pelacakan wajib diisi"})
+		return order, false
+	}
+
+	orderID, err := middleware.ParseOrderLookupToken(token)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Token pelacakan tidak valid atau sudah kedaluwarsa"})
+		return order, false
+	}
+
+	// Pesanan yang sudah masuk ke akun hanya bisa diakses lewat akun tersebut
+	if err := config.DB.Preload("OrderItems.Product").Where("user_id IS NULL").First(&order, orderID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pesanan tidak ditemukan"})
+		return order, false
+	}
+
+	return order, true
+}
+
+// sendGuestOrderEmail mengirim konfirmasi pesanan beserta link pelacakan ke email tamu
+func sendGuestOrderEmail(order models.Order, token string) {
+	err := mailer.Send(mailer.Message{
+		To:      order.GuestEmail,
+		Subject: fmt.Sprintf("Pesanan #%d diterima", order.ID),
+		Body: fmt.Sprintf("Halo %s,\n\nPesanan #%d sebesar Rp%.0f sudah kami terima.\n"+
+			"Lihat status atau batalkan pesanan melalui link berikut:\n\n%s\n\n"+
+			"Daftar dengan email ini untuk melihat semua pesanan Anda di aplikasi.\n",
+			order.GuestName, order.ID, order.TotalAmount, appLink("/guest/orders/lookup", token)),
+	})
+	if err != nil {
+		log.Printf("Gagal mengirim email pesanan tamu %d: %v", order.ID, err)
+	}
+}
+
+func guestOrderResponse(order models.Order) gin.H {
+	items := make([]gin.H, 0, len(order.OrderItems))
+	for _, item := range order.OrderItems {
+		items = append(items, gin.H{
+			"product_id":   item.ProductID,
+			"product_name": item.Product.Name,
+			"sku":          item.SKU,
+			"variant":      item.VariantLabel,
+			"quantity":     item.Quantity,
+			"price":        item.Price,
+		})
+	}
+
+	return gin.H{
+		"id":               order.ID,
+		"status":           order.Status,
+		"total_amount":     order.TotalAmount,
+		"name":             order.GuestName,
+		"email":            order.GuestEmail,
+		"phone":            order.GuestPhone,
+		"shipping_address": order.ShippingAddress,
+		"payment_method":   order.PaymentMethod,
+		"items":            items,
+		"created_at":       order.CreatedAt,
+		"updated_at":       order.UpdatedAt,
+	}
+}
@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"ecom-be/config"
+	"ecom-be/models"
+	"ecom-be/throttle"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Kebijakan penguncian login. Batas per IP lebih longgar karena satu IP bisa
+// dipakai banyak user (NAT, jaringan kantor).
+var (
+	emailLoginPolicy = throttle.Policy{FreeAttempts: 5, BaseDelay: 30 * time.Second, MaxDelay: 15 * time.Minute, Window: time.Hour}
+	ipLoginPolicy    = throttle.Policy{FreeAttempts: 20, BaseDelay: 30 * time.Second, MaxDelay: 15 * time.Minute, Window: time.Hour}
+)
+
+// loginThrottleKeys mengembalikan kunci throttle untuk email dan IP client
+func loginThrottleKeys(c *gin.Context, email string) map[string]throttle.Policy {
+	return map[string]throttle.Policy{
+		"email:" + strings.ToLower(strings.TrimSpace(email)): emailLoginPolicy,
+		"ip:" + c.ClientIP(): ipLoginPolicy,
+	}
+}
+
+// checkThrottle menolak request dengan 429 dan Retry-After jika salah satu kunci sedang terkunci
+func checkThrottle(c *gin.Context, keys map[string]throttle.Policy) bool {
+	return checkRateLimit(c, keys, "Terlalu banyak percobaan gagal")
+}
+
+// checkRateLimit seperti checkThrottle, dengan alasan penolakan sendiri di pesan error
+func checkRateLimit(c *gin.Context, keys map[string]throttle.Policy, reason string) bool {
+	now := time.Now()
+	var wait time.Duration
+
+	for key := range keys {
+		state, err := throttle.Default.Get(key)
+		if err != nil {
+			log.Printf("Gagal membaca status throttle %s: %v", key, err)
+			continue
+		}
+		if retry := state.RetryAfter(now); retry > wait {
+			wait = retry
+		}
+	}
+
+	if wait == 0 {
+		return true
+	}
+
+	seconds := int(math.Ceil(wait.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":       fmt.Sprintf("%s, coba lagi dalam %d detik", reason, seconds),
+		"retry_after": seconds,
+	})
+	return false
+}
+
+// recordThrottleFailure menambah hitungan gagal untuk setiap kunci
+func recordThrottleFailure(keys map[string]throttle.Policy) {
+	now := time.Now()
+	for key, policy := range keys {
+		if _, err := throttle.Default.RecordFailure(key, policy, now); err != nil {
+			log.Printf("Gagal mencatat kegagalan throttle %s: %v", key, err)
+		}
+	}
+}
+
+// resetThrottle menghapus hitungan gagal setelah login berhasil
+func resetThrottle(keys ...string) {
+	for _, key := range keys {
+		if err := throttle.Default.Reset(key); err != nil {
+			log.Printf("Gagal mereset throttle %s: %v", key, err)
+		}
+	}
+}
+
+// recordLoginAttempt menyimpan riwayat percobaan login untuk ditinjau admin
+func recordLoginAttempt(c *gin.Context, email string, userID *uint, success bool, reason string) {
+	attempt := models.LoginAttempt{
+		Email:     strings.ToLower(strings.TrimSpace(email)),
+		UserID:    userID,
+		IP:        c.ClientIP(),
+		UserAgent: truncate(c.Request.UserAgent(), 255),
+		Success:   success,
+		Reason:    reason,
+	}
+	if err := config.DB.Create(&attempt).Error; err != nil {
+		log.Printf("Gagal mencatat percobaan login: %v", err)
+	}
+}
+
+// GetLoginAttempts menampilkan riwayat percobaan login (admin only)
+func GetLoginAttempts(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	query := config.DB.Model(&models.LoginAttempt{})
+
+	if email := c.Query("email"); email != "" {
+		query = query.Where("email = ?", strings.ToLower(email))
+	}
+	if ip := c.Query("ip"); ip != "" {
+		query = query.Where("ip = ?", ip)
+	}
+	if userID := c.Query("user_id"); userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+	if success := c.Query("success"); success != "" {
+		query = query.Where("success = ?", success == "true" || success == "1")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil riwayat login"})
+		return
+	}
+
+	var attempts []models.LoginAttempt
+	if err := query.Order("created_at DESC").Offset(offset).Limit(limit).Find(&attempts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil riwayat login"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"attempts": attempts,
+		"meta": gin.H{
+			"page":     page,
+			"limit":    limit,
+			"total":    total,
+			"lastPage": (int(total) + limit - 1) / limit,
+		},
+	})
+}
+
+func truncate(s string, max int) string {
+	if len(s) > max {
+		return s[:max]
+	}
+	return s
+}
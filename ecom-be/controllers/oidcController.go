@@ -0,0 +1,158 @@
+package controllers
+
+import (
+	"ecom-be/config"
+	"ecom-be/middleware"
+	"ecom-be/models"
+	"ecom-be/oidc"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// errOIDCUnverifiedAccount berarti email OIDC sudah dipakai akun yang belum diverifikasi
+var errOIDCUnverifiedAccount = errors.New("akun dengan email ini belum diverifikasi")
+
+// OIDCLogin login atau registrasi menggunakan ID token dari penyedia OIDC (Google, Apple, ...)
+func OIDCLogin(c *gin.Context) {
+	var input struct {
+		IDToken string `json:"id_token" binding:"required"`
+		Nonce   string `json:"nonce"`
+		// Name dipakai jika ID token tidak berisi nama (Apple hanya mengirim nama ke aplikasi)
+		Name string `json:"name" binding:"max=100"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	providerName := strings.ToLower(c.Param("provider"))
+	provider, err := oidc.Lookup(providerName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Penyedia login tidak didukung"})
+		return
+	}
+
+	identity, err := provider.Verify(c.Request.Context(), input.IDToken, input.Nonce)
+	if err != nil {
+		log.Printf("Verifikasi ID token %s gagal: %v", providerName, err)
+		recordLoginAttempt(c, "", nil, false, "oidc_invalid_token")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "ID token tidak valid"})
+		return
+	}
+
+	// Akun hanya dihubungkan berdasarkan email yang sudah diverifikasi penyedia
+	if identity.Email == "" || !identity.EmailVerified {
+		recordLoginAttempt(c, identity.Email, nil, false, "oidc_unverified_email")
+		c.JSON(http.StatusForbidden, gin.H{"error": "Email akun " + providerName + " belum diverifikasi"})
+		return
+	}
+
+	if identity.Name == "" {
+		identity.Name = strings.TrimSpace(input.Name)
+	}
+
+	user, err := findOrCreateOIDCUser(providerName, identity)
+	if errors.Is(err, errOIDCUnverifiedAccount) {
+		recordLoginAttempt(c, identity.Email, nil, false, "oidc_unverified_account")
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Email ini sudah terdaftar tetapi belum diverifikasi. Login dengan password atau gunakan lupa password, lalu hubungkan akun " + providerName + " setelah email diverifikasi",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal login dengan " + providerName})
+		return
+	}
+
+	if user.IsSuspended() {
+		recordLoginAttempt(c, user.Email, &user.ID, false, "suspended")
+		c.JSON(http.StatusForbidden, gin.H{"error": "Akun Anda telah dinonaktifkan"})
+		return
+	}
+
+	recordLoginAttempt(c, user.Email, &user.ID, true, "oidc:"+providerName)
+	completeLogin(c, user)
+}
+
+// findOrCreateOIDCUser mencari user dari identitas OIDC, menghubungkan ke akun
+// dengan email yang sama, atau membuat akun baru
+func findOrCreateOIDCUser(provider string, identity *oidc.Identity) (models.User, error) {
+	var user models.User
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// Identitas yang sudah pernah terhubung
+		var link models.UserIdentity
+		err := tx.Preload("User").Where("provider = ? AND subject = ?", provider, identity.Subject).First(&link).Error
+		if err == nil {
+			user = link.User
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		// Hubungkan ke akun yang sudah ada dengan email yang sama. Akun yang belum
+		// diverifikasi tidak dihubungkan: siapa pun bisa mendaftar dengan email orang
+		// lain lebih dulu dan tetap memegang password-nya setelah akun diambil alih.
+		err = tx.Where("email = ?", identity.Email).First(&user).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			user, err = newOIDCUser(tx, identity)
+		} else if err == nil && !user.IsEmailVerified() {
+			return errOIDCUnverifiedAccount
+		}
+		if err != nil {
+			return err
+		}
+
+		err = tx.Create(&models.UserIdentity{
+			UserID:   user.ID,
+			Provider: provider,
+			Subject:  identity.Subject,
+			Email:    identity.Email,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return claimGuestOrders(tx, user.ID, identity.Email)
+	})
+
+	return user, err
+}
+
+// newOIDCUser membuat akun baru dengan password acak (login hanya lewat OIDC
+// sampai user mengatur password melalui reset password)
+func newOIDCUser(tx *gorm.DB, identity *oidc.Identity) (models.User, error) {
+	randomPassword, err := middleware.GenerateOpaqueToken()
+	if err != nil {
+		return models.User{}, err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(randomPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	name := identity.Name
+	if name == "" {
+		name = strings.SplitN(identity.Email, "@", 2)[0]
+	}
+
+	now := time.Now()
+	user := models.User{
+		Name:            name,
+		Email:           identity.Email,
+		Password:        string(hashedPassword),
+		Role:            models.RoleUser,
+		EmailVerifiedAt: &now,
+	}
+	err = tx.Create(&user).Error
+	return user, err
+}
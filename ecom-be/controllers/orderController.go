@@ -4,10 +4,14 @@ import (
 	"ecom-be/config"
 	"ecom-be/middleware"
 	"ecom-be/models"
+	"ecom-be/push"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/clause"
 )
 
 // CreateOrder membuat pesanan baru
@@ -17,9 +21,10 @@ func CreateOrder(c *gin.Context) {
 	claims := userClaims.(*middleware.Claims)
 	userID := claims.UserID
 
-	// Parse input
+	// Parse input: alamat diambil dari address_id (buku alamat) atau shipping_address (teks bebas)
 	var input struct {
-		ShippingAddress string `json:"shipping_address" binding:"required"`
+		AddressID       *uint  `json:"address_id"`
+		ShippingAddress string `json:"shipping_address" binding:"required_without=AddressID"`
 		PaymentMethod   string `json:"payment_method" binding:"required"`
 	}
 
@@ -28,33 +33,69 @@ func CreateOrder(c *gin.Context) {
 		return
 	}
 
+	// Checkout hanya untuk akun yang emailnya sudah diverifikasi
+	var user models.User
+	if err := config.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User tidak ditemukan"})
+		return
+	}
+	if !user.IsEmailVerified() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Verifikasi email Anda terlebih dahulu sebelum checkout"})
+		return
+	}
+
+	// Salin alamat dari buku alamat sebagai snapshot pesanan
+	var shippingDetail models.AddressFields
+	if input.AddressID != nil {
+		var address models.Address
+		if err := config.DB.Where("id = ? AND user_id = ?", *input.AddressID, userID).First(&address).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Alamat tidak ditemukan"})
+			return
+		}
+		shippingDetail = address.AddressFields
+		input.ShippingAddress = address.String()
+	}
+
 	// Cari cart milik user
 	var cart models.Cart
-	if err := config.DB.Preload("CartItems.Product").Where("user_id = ?", userID).First(&cart).Error; err != nil {
+	if err := config.DB.Preload("CartItems.Product").Preload("CartItems.Variant").Where("user_id = ?", userID).First(&cart).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Keranjang tidak ditemukan"})
 		return
 	}
 
+	order, ok := placeOrder(c, cart, models.Order{
+		UserID:          &userID,
+		ShippingAddress: input.ShippingAddress,
+		ShippingDetail:  shippingDetail,
+		PaymentMethod:   input.PaymentMethod,
+	})
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Pesanan berhasil dibuat",
+		"order":   order,
+	})
+}
+
+// placeOrder membuat pesanan dari isi keranjang: menyimpan item, mengurangi
+// stok, dan mengosongkan keranjang. Dipakai untuk checkout user dan tamu.
+func placeOrder(c *gin.Context, cart models.Cart, order models.Order) (models.Order, bool) {
 	// Validasi: cart harus memiliki item
 	if len(cart.CartItems) == 0 {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Keranjang kosong"})
-		return
+		return order, false
 	}
 
 	// Hitung total
 	var totalAmount float64 = 0
 	for _, item := range cart.CartItems {
-		totalAmount += item.Product.Price * float64(item.Quantity)
+		totalAmount += item.UnitPrice() * float64(item.Quantity)
 	}
 
-	// Buat order baru
-	order := models.Order{
-		UserID:          userID,
-		TotalAmount:     totalAmount,
-		Status:          models.OrderStatusPending,
-		ShippingAddress: input.ShippingAddress,
-		PaymentMethod:   input.PaymentMethod,
-	}
+	order.TotalAmount = totalAmount
+	order.Status = models.OrderStatusPending
 
 	// Transaction: create order & order items, update stock, clear cart
 	tx := config.DB.Begin()
@@ -63,7 +104,7 @@ func CreateOrder(c *gin.Context) {
 	if err := tx.Create(&order).Error; err != nil {
 		tx.Rollback()
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat pesanan"})
-		return
+		return order, false
 	}
 
 	// Buat order items & update stok
@@ -73,16 +114,7 @@ func CreateOrder(c *gin.Context) {
 		if err := tx.First(&product, cartItem.ProductID).Error; err != nil {
 			tx.Rollback()
 			c.JSON(http.StatusNotFound, gin.H{"error": "Produk tidak ditemukan"})
-			return
-		}
-
-		if product.Stock < cartItem.Quantity {
-			tx.Rollback()
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error":     "Stok produk tidak mencukupi",
-				"productId": product.ID,
-			})
-			return
+			return order, false
 		}
 
 		// Buat order item
@@ -93,18 +125,49 @@ func CreateOrder(c *gin.Context) {
 			Price:     product.Price,
 		}
 
+		// Produk bervarian dicek stoknya per varian; SKU dan opsinya disalin ke pesanan
+		variant, err := findProductVariant(tx, product.ID, cartItem.VariantID)
+		if err != nil {
+			tx.Rollback()
+			if errors.Is(err, errVariantRequired) {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":     "Pilih varian produk terlebih dahulu",
+					"productId": product.ID,
+				})
+			} else {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Varian produk tidak ditemukan"})
+			}
+			return order, false
+		}
+		stock := product.Stock
+		if variant != nil {
+			stock = variant.Stock
+			orderItem.VariantID = &variant.ID
+			orderItem.SKU = variant.SKU
+			orderItem.VariantLabel = variant.Label()
+			orderItem.Price = variant.PriceFor(product)
+		}
+
+		if stock < cartItem.Quantity {
+			tx.Rollback()
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":     "Stok produk tidak mencukupi",
+				"productId": product.ID,
+			})
+			return order, false
+		}
+
 		if err := tx.Create(&orderItem).Error; err != nil {
 			tx.Rollback()
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat item pesanan"})
-			return
+			return order, false
 		}
 
-		// Update stok produk
-		product.Stock -= cartItem.Quantity
-		if err := tx.Save(&product).Error; err != nil {
+		// Update stok produk dan variannya
+		if err := adjustStock(tx, product.ID, orderItem.VariantID, -cartItem.Quantity); err != nil {
 			tx.Rollback()
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengupdate stok produk"})
-			return
+			return order, false
 		}
 	}
 
@@ -112,16 +175,13 @@ func CreateOrder(c *gin.Context) {
 	if err := tx.Where("cart_id = ?", cart.ID).Delete(&models.CartItem{}).Error; err != nil {
 		tx.Rollback()
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengosongkan keranjang"})
-		return
+		return order, false
 	}
 
 	// Commit transaksi
 	tx.Commit()
 
-	c.JSON(http.StatusCreated, gin.H{
-		"message": "Pesanan berhasil dibuat",
-		"order":   order,
-	})
+	return order, true
 }
 
 // GetOrders menampilkan semua pesanan user
@@ -210,21 +270,44 @@ func CancelOrder(c *gin.Context) {
 		return
 	}
 
+	if !cancelOrder(c, order) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Pesanan berhasil dibatalkan"})
+}
+
+// cancelOrder membatalkan pesanan dan mengembalikan stok produknya.
+// Dipakai untuk pembatalan oleh user dan oleh tamu.
+func cancelOrder(c *gin.Context, order models.Order) bool {
 	// Validasi: hanya bisa membatalkan pesanan dengan status pending atau processing
 	if order.Status != models.OrderStatusPending && order.Status != models.OrderStatusProcessing {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Pesanan tidak dapat dibatalkan"})
-		return
+		return false
 	}
 
 	// Transaction: update order status & kembalikan stok
 	tx := config.DB.Begin()
 
+	// Baca ulang pesanan dengan lock agar pembatalan bersamaan atau perubahan
+	// status oleh admin tidak mengembalikan stok dua kali
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, order.ID).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data pesanan"})
+		return false
+	}
+	if order.Status != models.OrderStatusPending && order.Status != models.OrderStatusProcessing {
+		tx.Rollback()
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Pesanan tidak dapat dibatalkan"})
+		return false
+	}
+
 	// Load order items untuk mengembalikan stok
 	var orderItems []models.OrderItem
 	if err := tx.Where("order_id = ?", order.ID).Find(&orderItems).Error; err != nil {
 		tx.Rollback()
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data item pesanan"})
-		return
+		return false
 	}
 
 	// Kembalikan stok
@@ -233,14 +316,26 @@ func CancelOrder(c *gin.Context) {
 		if err := tx.First(&product, item.ProductID).Error; err != nil {
 			tx.Rollback()
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data produk"})
-			return
+			return false
+		}
+
+		// Varian yang sudah dihapus tidak lagi memiliki stok untuk dikembalikan
+		if item.VariantID != nil {
+			var count int64
+			if err := tx.Model(&models.ProductVariant{}).Where("id = ?", *item.VariantID).Count(&count).Error; err != nil {
+				tx.Rollback()
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data varian produk"})
+				return false
+			}
+			if count == 0 {
+				continue
+			}
 		}
 
-		product.Stock += item.Quantity
-		if err := tx.Save(&product).Error; err != nil {
+		if err := adjustStock(tx, product.ID, item.VariantID, item.Quantity); err != nil {
 			tx.Rollback()
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengembalikan stok produk"})
-			return
+			return false
 		}
 	}
 
@@ -249,13 +344,12 @@ func CancelOrder(c *gin.Context) {
 	if err := tx.Save(&order).Error; err != nil {
 		tx.Rollback()
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membatalkan pesanan"})
-		return
+		return false
 	}
 
 	// Commit transaksi
 	tx.Commit()
-
-	c.JSON(http.StatusOK, gin.H{"message": "Pesanan berhasil dibatalkan"})
+	return true
 }
 
 // GetAllOrders menampilkan semua pesanan (admin only)
@@ -335,14 +429,48 @@ func UpdateOrderStatus(c *gin.Context) {
 	}
 
 	// Update status
+	previousStatus := order.Status
 	order.Status = models.OrderStatus(input.Status)
 	if err := config.DB.Save(&order).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengupdate status pesanan"})
 		return
 	}
 
+	// Kabari user lewat push notification saat pesanan dikirim atau sampai.
+	// Dikirim di background agar penyedia push yang lambat tidak menahan
+	// respons admin. Pesanan tamu tidak memiliki perangkat terdaftar.
+	if order.Status != previousStatus && order.UserID != nil {
+		if msg, ok := orderStatusPush(order); ok {
+			go notifyUser(*order.UserID, msg)
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Status pesanan berhasil diubah",
 		"order":   order,
 	})
-} 
\ No newline at end of file
+} 
+
+// orderStatusPush menyusun push notification untuk perubahan status pesanan
+func orderStatusPush(order models.Order) (push.Message, bool) {
+	data := map[string]string{
+		"order_id": strconv.Itoa(int(order.ID)),
+		"status":   string(order.Status),
+	}
+
+	switch order.Status {
+	case models.OrderStatusShipped:
+		return push.Message{
+			Title: "Pesanan sedang dikirim",
+			Body:  fmt.Sprintf("Pesanan #%d sedang dalam perjalanan ke alamat Anda", order.ID),
+			Data:  data,
+		}, true
+	case models.OrderStatusDelivered:
+		return push.Message{
+			Title: "Pesanan telah sampai",
+			Body:  fmt.Sprintf("Pesanan #%d telah diterima. Terima kasih telah berbelanja!", order.ID),
+			Data:  data,
+		}, true
+	}
+	return push.Message{}, false
+}
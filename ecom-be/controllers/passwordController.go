@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"ecom-be/config"
+	"ecom-be/mailer"
+	"ecom-be/middleware"
+	"ecom-be/models"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// ForgotPassword mengirim email berisi token reset password.
+// Respons selalu sama agar tidak membocorkan email mana yang terdaftar.
+func ForgotPassword(c *gin.Context) {
+	var input struct {
+		Email string `json:"email" binding:"required,email"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := gin.H{"message": "Jika email terdaftar, link reset password telah dikirim"}
+
+	var user models.User
+	if err := config.DB.Where("email = ?", input.Email).First(&user).Error; err != nil {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	rawToken, err := middleware.GenerateOpaqueToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat token reset password"})
+		return
+	}
+
+	ttl := config.GetDuration("PASSWORD_RESET_TTL", time.Hour)
+	resetToken := models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: middleware.HashToken(rawToken),
+		ExpiresAt: time.Now().Add(ttl),
+	}
+
+	if err := config.DB.Create(&resetToken).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat token reset password"})
+		return
+	}
+
+	err = mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Reset password akun Anda",
+		Body: fmt.Sprintf(
+			"Halo %s,\n\nKami menerima permintaan untuk mereset password akun Anda.\n"+
+				"Buka link berikut untuk membuat password baru:\n\n%s\n\n"+
+				"Atau gunakan token ini di aplikasi: %s\n\n"+
+				"Link berlaku selama %s. Abaikan email ini jika Anda tidak memintanya.\n",
+			user.Name, appLink("/reset-password", rawToken), rawToken, ttl,
+		),
+	})
+	if err != nil {
+		log.Printf("Gagal mengirim email reset password ke %s: %v", user.Email, err)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ResetPassword mengganti password menggunakan token reset dan mencabut semua sesi user
+func ResetPassword(c *gin.Context) {
+	var input struct {
+		Token    string `json:"token" binding:"required"`
+		Password string `json:"password" binding:"required,min=6"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mereset password"})
+		return
+	}
+
+	var resetToken models.PasswordResetToken
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("token_hash = ? AND used_at IS NULL AND expires_at > ?",
+			middleware.HashToken(input.Token), time.Now()).First(&resetToken).Error; err != nil {
+			return err
+		}
+
+		// Tandai token terpakai; cek RowsAffected agar token tidak bisa dipakai dua kali secara bersamaan
+		now := time.Now()
+		result := tx.Model(&models.PasswordResetToken{}).
+			Where("user_id = ? AND used_at IS NULL", resetToken.UserID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return tx.Model(&models.User{}).
+			Where("id = ?", resetToken.UserID).
+			Update("password", string(hashedPassword)).Error
+	})
+
+	if err == gorm.ErrRecordNotFound {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token reset password tidak valid atau sudah kedaluwarsa"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mereset password"})
+		return
+	}
+
+	// Paksa login ulang di semua perangkat
+	if err := middleware.RevokeAllUserTokens(resetToken.UserID, ""); err != nil {
+		log.Printf("Gagal mencabut sesi user %d setelah reset password: %v", resetToken.UserID, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password berhasil direset, silakan login kembali"})
+}
+
+// appLink membuat link ke aplikasi client (APP_URL) dengan token sebagai query
+func appLink(path, token string) string {
+	return strings.TrimRight(os.Getenv("APP_URL"), "/") + path + "?token=" + url.QueryEscape(token)
+}
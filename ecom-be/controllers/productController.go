@@ -1,12 +1,15 @@
 package controllers
 
 import (
+	"context"
 	"ecom-be/config"
 	"ecom-be/models"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // CreateProduct membuat produk baru (hanya admin)
@@ -17,10 +20,12 @@ func CreateProduct(c *gin.Context) {
 		return
 	}
 
-	if result := config.DB.Create(&input); result.Error != nil {
+	// Kategori dan varian diatur lewat endpoint terpisah
+	if result := config.DB.Omit(clause.Associations).Create(&input); result.Error != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat produk"})
 		return
 	}
+	reindexProducts([]uint{input.ID})
 	
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "Produk berhasil dibuat",
@@ -28,31 +33,63 @@ func CreateProduct(c *gin.Context) {
 	})
 }
 
-// GetProducts menampilkan semua produk
+// GetProducts menampilkan produk dengan filter, urutan dan facet.
+// Filter: search, category, min_price, max_price, in_stock, attr[nama]=nilai1,nilai2.
+// Urutan (sort): relevance, newest, price_asc, price_desc, name_asc, name_desc, best_selling.
 func GetProducts(c *gin.Context) {
 	var products []models.Product
 	
 	// Paginasi
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
 	offset := (page - 1) * limit
 	
-	// Query produk dengan paginasi
-	query := config.DB.Offset(offset).Limit(limit)
+	filter, ok := parseProductFilter(c)
+	if !ok {
+		return
+	}
+
+	// Hasil pencarian diurutkan berdasarkan relevansi kecuali diminta lain
+	sortBy := c.Query("sort")
+	if sortBy == "" {
+		sortBy = "newest"
+		if filter.Search != "" {
+			sortBy = "relevance"
+		}
+	}
+	if _, ok := productSorts[sortBy]; !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter sort tidak valid"})
+		return
+	}
 	
-	// Filter berdasarkan nama produk jika ada
-	if search := c.Query("search"); search != "" {
-		query = query.Where("name ILIKE ?", "%"+search+"%")
+	// Hitung total produk yang sesuai filter
+	var total int64
+	if err := filter.query("").Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data produk"})
+		return
 	}
 	
+	// Query produk dengan paginasi, beserta gambar utamanya untuk ditampilkan di daftar
+	query := applyProductSort(filter.query(""), sortBy, filter).Offset(offset).Limit(limit).Preload("Images", "is_primary = ?", true)
 	if err := query.Find(&products).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data produk"})
 		return
 	}
-	
-	// Hitung total produk
-	var total int64
-	config.DB.Model(&models.Product{}).Count(&total)
+	for i := range products {
+		fillImageURLs(products[i].Images)
+	}
+
+	facets, err := productFacets(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghitung facet produk"})
+		return
+	}
 	
 	c.JSON(http.StatusOK, gin.H{
 		"products": products,
@@ -61,7 +98,9 @@ func GetProducts(c *gin.Context) {
 			"limit":     limit,
 			"total":     total,
 			"lastPage":  (int(total) + limit - 1) / limit,
+			"truncated": filter.SearchTruncated,
 		},
+		"facets": facets,
 	})
 }
 
@@ -70,10 +109,16 @@ func GetProduct(c *gin.Context) {
 	var product models.Product
 	id := c.Param("id")
 	
-	if err := config.DB.First(&product, id).Error; err != nil {
+	err := config.DB.Preload("Categories").
+		Preload("Options", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		Preload("Variants.Options").
+		Preload("Images", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		First(&product, id).Error
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Produk tidak ditemukan"})
 		return
 	}
+	fillImageURLs(product.Images)
 	
 	c.JSON(http.StatusOK, product)
 }
@@ -96,11 +141,20 @@ func UpdateProduct(c *gin.Context) {
 		return
 	}
 	
+	// Stok produk bervarian adalah total stok variannya, jadi tidak bisa diubah langsung
+	omit := []string{clause.Associations}
+	var variants int64
+	config.DB.Model(&models.ProductVariant{}).Where("product_id = ?", product.ID).Count(&variants)
+	if variants > 0 {
+		omit = append(omit, "Stock")
+	}
+
 	// Update produk
-	if err := config.DB.Model(&product).Updates(input).Error; err != nil {
+	if err := config.DB.Model(&product).Omit(omit...).Updates(input).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengupdate produk"})
 		return
 	}
+	reindexProducts([]uint{product.ID})
 	
 	// Ambil data produk yang telah diupdate
 	config.DB.First(&product, id)
@@ -122,11 +176,44 @@ func DeleteProduct(c *gin.Context) {
 		return
 	}
 	
-	// Hapus produk
-	if err := config.DB.Delete(&product).Error; err != nil {
+	var images []models.ProductImage
+	config.DB.Where("product_id = ?", product.ID).Find(&images)
+
+	// Hapus produk beserta entri wishlist, kategori, varian, dan gambarnya
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("product_id = ?", product.ID).Delete(&models.WishlistItem{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&product).Association("Categories").Clear(); err != nil {
+			return err
+		}
+		variantIDs := tx.Model(&models.ProductVariant{}).Select("id").Where("product_id = ?", product.ID)
+		if err := tx.Where("variant_id IN (?)", variantIDs).Delete(&models.VariantOption{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("product_id = ?", product.ID).Delete(&models.ProductVariant{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("product_id = ?", product.ID).Delete(&models.ProductOption{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("product_id = ?", product.ID).Delete(&models.ProductImage{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&product).Error
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus produk"})
 		return
 	}
+	unindexProduct(product.ID)
+
+	// File gambar dihapus setelah data produk terhapus
+	ctx, cancel := context.WithTimeout(c.Request.Context(), storageTimeout)
+	defer cancel()
+	for _, image := range images {
+		deleteImageFiles(ctx, image)
+	}
 	
 	c.JSON(http.StatusOK, gin.H{"message": "Produk berhasil dihapus"})
 }
@@ -0,0 +1,364 @@
+package controllers
+
+import (
+	"ecom-be/config"
+	"ecom-be/models"
+	"ecom-be/search"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Nama facet, dipakai untuk menghitung facet tanpa filternya sendiri
+const (
+	facetCategory  = "category"
+	facetPrice     = "price"
+	facetStock     = "in_stock"
+	facetAttribute = "attr:"
+)
+
+// Harga dan stok efektif satu baris offers: harga varian jika ditimpa, dan
+// harga serta stok produk untuk produk tanpa varian
+const (
+	offerPrice = "COALESCE(product_variants.price, products.price)"
+	offerStock = "COALESCE(product_variants.stock, products.stock)"
+)
+
+// productSorts memetakan parameter sort ke urutan query. Harga diurutkan
+// berdasarkan harga termurah varian yang cocok dengan filter, best_selling
+// berdasarkan jumlah terjual dari pesanan yang tidak dibatalkan, dan relevance
+// mengikuti urutan hasil pencarian full-text.
+var productSorts = map[string]string{
+	"relevance":    "",
+	"newest":       "products.created_at DESC, products.id DESC",
+	"price_asc":    "offer.price ASC, products.id ASC",
+	"price_desc":   "offer.price DESC, products.id DESC",
+	"name_asc":     "products.name ASC, products.id ASC",
+	"name_desc":    "products.name DESC, products.id DESC",
+	"best_selling": "COALESCE(sales.sold, 0) DESC, products.id DESC",
+}
+
+// productFilter berisi filter daftar produk dari query string
+type productFilter struct {
+	Search          string
+	SearchIDs       []uint // hasil pencarian full-text, terurut dari yang paling relevan
+	SearchTruncated bool   // pencarian cocok dengan lebih dari maxSearchResults produk
+	CategoryIDs     []uint // kategori beserta turunannya; nil berarti tanpa filter
+	MinPrice        *float64
+	MaxPrice        *float64
+	InStock         bool
+	Attributes      map[string][]string // opsi varian, mis. size: [M, L]
+}
+
+// parseProductFilter membaca filter dari query string, atau menulis respons error
+func parseProductFilter(c *gin.Context) (productFilter, bool) {
+	filter := productFilter{Search: strings.TrimSpace(c.Query("search"))}
+
+	// Pencarian memakai index full-text atas nama, deskripsi, dan kategori produk
+	if filter.Search != "" {
+		results, total, err := search.Search(filter.Search, maxSearchResults)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mencari produk"})
+			return filter, false
+		}
+		filter.SearchIDs = make([]uint, 0, len(results))
+		for _, result := range results {
+			filter.SearchIDs = append(filter.SearchIDs, result.ID)
+		}
+		filter.SearchTruncated = total > len(results)
+	}
+
+	// Kategori berupa ID atau slug, termasuk sub-kategorinya
+	if category := c.Query("category"); category != "" {
+		categoryIDs, err := findCategoryFilter(category)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data kategori"})
+			return filter, false
+		}
+		if categoryIDs == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Kategori tidak ditemukan"})
+			return filter, false
+		}
+		filter.CategoryIDs = categoryIDs
+	}
+
+	for param, target := range map[string]**float64{"min_price": &filter.MinPrice, "max_price": &filter.MaxPrice} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		price, err := strconv.ParseFloat(value, 64)
+		if err != nil || price < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": param + " harus berupa angka positif"})
+			return filter, false
+		}
+		*target = &price
+	}
+
+	inStock := c.Query("in_stock")
+	filter.InStock = inStock == "true" || inStock == "1"
+
+	// Atribut dikirim sebagai attr[size]=M,L; beberapa nilai berarti salah satunya
+	for name, value := range c.QueryMap("attr") {
+		var values []string
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		if len(values) > 0 {
+			if filter.Attributes == nil {
+				filter.Attributes = map[string][]string{}
+			}
+			filter.Attributes[strings.ToLower(name)] = values
+		}
+	}
+
+	return filter, true
+}
+
+// query menyusun query produk dengan semua filter kecuali filter milik facet skip
+func (f productFilter) query(skip string) *gorm.DB {
+	query := config.DB.Model(&models.Product{})
+
+	if f.Search != "" {
+		query = query.Where("products.id IN ?", f.SearchIDs)
+	}
+	if f.CategoryIDs != nil && skip != facetCategory {
+		query = query.Where("products.id IN (?)",
+			config.DB.Table("product_categories").Select("product_id").Where("category_id IN ?", f.CategoryIDs))
+	}
+	if f.filtersOffers(skip) {
+		query = query.Where("products.id IN (?)", f.offers(skip).Select("products.id"))
+	}
+
+	return query
+}
+
+// offers menyusun query varian yang cocok dengan filter harga, stok, dan
+// atribut sekaligus, kecuali filter milik facet skip. Produk tanpa varian
+// muncul sebagai satu baris dengan kolom varian NULL.
+func (f productFilter) offers(skip string) *gorm.DB {
+	query := config.DB.Table("products").
+		Joins("LEFT JOIN product_variants ON product_variants.product_id = products.id")
+
+	if skip != facetPrice {
+		if f.MinPrice != nil {
+			query = query.Where(offerPrice+" >= ?", *f.MinPrice)
+		}
+		if f.MaxPrice != nil {
+			query = query.Where(offerPrice+" <= ?", *f.MaxPrice)
+		}
+	}
+	if f.InStock && skip != facetStock {
+		query = query.Where(offerStock + " > 0")
+	}
+	for name, values := range f.Attributes {
+		if skip == facetAttribute+name {
+			continue
+		}
+		query = query.Where("product_variants.id IN (?)", config.DB.Table("variant_options").
+			Select("variant_id").Where("name = ? AND value IN ?", name, values))
+	}
+
+	return query
+}
+
+// filtersOffers melaporkan apakah offers(skip) memiliki filter
+func (f productFilter) filtersOffers(skip string) bool {
+	if skip != facetPrice && (f.MinPrice != nil || f.MaxPrice != nil) {
+		return true
+	}
+	if f.InStock && skip != facetStock {
+		return true
+	}
+	for name := range f.Attributes {
+		if skip != facetAttribute+name {
+			return true
+		}
+	}
+	return false
+}
+
+// applyProductSort menambahkan urutan ke query daftar produk
+func applyProductSort(query *gorm.DB, sortBy string, f productFilter) *gorm.DB {
+	switch sortBy {
+	case "relevance":
+		if len(f.SearchIDs) == 0 {
+			return query.Order(productSorts["newest"])
+		}
+		return query.Order(clause.OrderBy{Expression: clause.Expr{
+			SQL: "FIELD(products.id, ?)", Vars: []interface{}{f.SearchIDs}, WithoutParentheses: true,
+		}})
+	case "best_selling":
+		sales := config.DB.Table("order_items").
+			Select("order_items.product_id, SUM(order_items.quantity) AS sold").
+			Joins("JOIN orders ON orders.id = order_items.order_id").
+			Where("orders.status <> ?", models.OrderStatusCancelled).
+			Group("order_items.product_id")
+		query = query.Select("products.*").Joins("LEFT JOIN (?) AS sales ON sales.product_id = products.id", sales)
+	case "price_asc", "price_desc":
+		offer := f.offers("").Select("products.id AS product_id, MIN(" + offerPrice + ") AS price").Group("products.id")
+		query = query.Select("products.*").Joins("JOIN (?) AS offer ON offer.product_id = products.id", offer)
+	}
+	return query.Order(productSorts[sortBy])
+}
+
+// productFacets menghitung jumlah produk untuk setiap pilihan filter. Setiap
+// facet dihitung dengan filter lain yang aktif, tetapi tanpa filternya sendiri,
+// sehingga aplikasi bisa menampilkan pilihan lain pada filter yang sama.
+func productFacets(f productFilter) (gin.H, error) {
+	categories, err := categoryFacet(f)
+	if err != nil {
+		return nil, err
+	}
+
+	var price struct {
+		Min *float64
+		Max *float64
+	}
+	err = f.offers(facetPrice).Where("products.id IN (?)", f.query(facetPrice).Select("products.id")).
+		Select("MIN(" + offerPrice + ") AS min, MAX(" + offerPrice + ") AS max").Scan(&price).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var stock struct {
+		InStock    int64
+		OutOfStock int64
+	}
+	// Produk dihitung tersedia jika salah satu varian yang cocok dengan filter lain masih ada stoknya
+	available := f.offers(facetStock).Where(offerStock + " > 0").Select("products.id")
+	err = f.query(facetStock).Select(
+		"COALESCE(SUM(CASE WHEN products.id IN (?) THEN 1 ELSE 0 END), 0) AS in_stock, "+
+			"COALESCE(SUM(CASE WHEN products.id IN (?) THEN 0 ELSE 1 END), 0) AS out_of_stock", available, available).
+		Scan(&stock).Error
+	if err != nil {
+		return nil, err
+	}
+
+	attributes, err := attributeFacet(f)
+	if err != nil {
+		return nil, err
+	}
+
+	return gin.H{
+		"categories": categories,
+		"price":      gin.H{"min": price.Min, "max": price.Max},
+		"stock":      gin.H{"in_stock": stock.InStock, "out_of_stock": stock.OutOfStock},
+		"attributes": attributes,
+	}, nil
+}
+
+// categoryFacet menghitung produk per kategori. Produk di sub-kategori ikut
+// dihitung pada induknya, sama seperti filter category.
+func categoryFacet(f productFilter) ([]gin.H, error) {
+	var pairs []struct {
+		ProductID  uint
+		CategoryID uint
+	}
+	err := config.DB.Table("product_categories").Select("product_id, category_id").
+		Where("product_id IN (?)", f.query(facetCategory).Select("products.id")).
+		Scan(&pairs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := loadCategories()
+	if err != nil {
+		return nil, err
+	}
+
+	parents := make(map[uint]*uint, len(categories))
+	for _, category := range categories {
+		parents[category.ID] = category.ParentID
+	}
+
+	products := make(map[uint]map[uint]bool)
+	for _, pair := range pairs {
+		// Naik ke semua induk; dibatasi jumlah kategori untuk berjaga dari data yang melingkar
+		id := &pair.CategoryID
+		for depth := 0; id != nil && depth < len(categories); depth++ {
+			if products[*id] == nil {
+				products[*id] = map[uint]bool{}
+			}
+			products[*id][pair.ProductID] = true
+			id = parents[*id]
+		}
+	}
+
+	result := make([]gin.H, 0, len(products))
+	for _, category := range categories {
+		if count := len(products[category.ID]); count > 0 {
+			data := categoryResponse(category)
+			data["count"] = count
+			result = append(result, data)
+		}
+	}
+	return result, nil
+}
+
+type attributeCount struct {
+	Name  string
+	Value string
+	Count int64
+}
+
+// attributeFacet menghitung produk per nilai opsi varian, dikelompokkan berdasarkan nama opsi
+func attributeFacet(f productFilter) (map[string][]gin.H, error) {
+	counts, err := attributeCounts(f, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// Atribut yang sedang difilter dihitung ulang tanpa filternya sendiri
+	grouped := map[string][]attributeCount{}
+	for _, row := range counts {
+		if _, selected := f.Attributes[row.Name]; !selected {
+			grouped[row.Name] = append(grouped[row.Name], row)
+		}
+	}
+	for name := range f.Attributes {
+		rows, err := attributeCounts(f, facetAttribute+name)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			if row.Name == name {
+				grouped[name] = append(grouped[name], row)
+			}
+		}
+	}
+
+	result := make(map[string][]gin.H, len(grouped))
+	for name, rows := range grouped {
+		sort.Slice(rows, func(i, j int) bool { return rows[i].Value < rows[j].Value })
+		values := make([]gin.H, 0, len(rows))
+		for _, row := range rows {
+			values = append(values, gin.H{"value": row.Value, "count": row.Count})
+		}
+		result[name] = values
+	}
+	return result, nil
+}
+
+// attributeCounts menghitung produk per nilai opsi dari varian yang cocok
+// dengan filter, kecuali filter milik facet skip
+func attributeCounts(f productFilter, skip string) ([]attributeCount, error) {
+	query := config.DB.Table("variant_options").
+		Select("variant_options.name, variant_options.value, COUNT(DISTINCT product_variants.product_id) AS count").
+		Joins("JOIN product_variants ON product_variants.id = variant_options.variant_id").
+		Where("product_variants.product_id IN (?)", f.query(skip).Select("products.id"))
+	if f.filtersOffers(skip) {
+		query = query.Where("product_variants.id IN (?)", f.offers(skip).Select("product_variants.id"))
+	}
+
+	var rows []attributeCount
+	err := query.Group("variant_options.name, variant_options.value").Scan(&rows).Error
+	return rows, err
+}
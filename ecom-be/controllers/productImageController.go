@@ -0,0 +1,366 @@
+package controllers
+
+import (
+	"context"
+	"ecom-be/config"
+	"ecom-be/imaging"
+	"ecom-be/models"
+	"ecom-be/storage"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// maxImageSize membatasi ukuran file gambar yang diunggah
+	maxImageSize = 5 << 20
+	// maxProductImages membatasi jumlah gambar per produk
+	maxProductImages = 10
+	// storageTimeout membatasi lama upload atau penghapusan file di storage
+	storageTimeout = 30 * time.Second
+)
+
+var errProductImageLimit = errors.New("jumlah gambar produk sudah mencapai batas maksimal")
+
+// UploadProductImage mengunggah gambar produk dari field multipart "image" (admin only).
+// Thumbnail dibuat otomatis; gambar pertama atau yang dikirim dengan is_primary=true
+// menjadi gambar utama.
+func UploadProductImage(c *gin.Context) {
+	product, ok := findProductParam(c)
+	if !ok {
+		return
+	}
+
+	// Sisakan ruang untuk field lain dan boundary multipart
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImageSize+1<<20)
+
+	file, header, err := c.Request.FormFile("image")
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Ukuran gambar maksimal 5 MB"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File gambar wajib dikirim di field image"})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxImageSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Gagal membaca file gambar"})
+		return
+	}
+	if header.Size > maxImageSize || len(data) > maxImageSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Ukuran gambar maksimal 5 MB"})
+		return
+	}
+
+	info, err := imaging.Inspect(data)
+	if errors.Is(err, imaging.ErrTooLarge) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Resolusi gambar maksimal 12 megapiksel"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Format gambar harus JPEG atau PNG"})
+		return
+	}
+
+	// Pengecekan awal agar file tidak diunggah jika batas sudah tercapai;
+	// batas dan gambar utama diputuskan ulang di dalam transaksi
+	var count int64
+	if err := config.DB.Model(&models.ProductImage{}).Where("product_id = ?", product.ID).Count(&count).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data gambar produk"})
+		return
+	}
+	if count >= maxProductImages {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Jumlah gambar produk sudah mencapai batas maksimal"})
+		return
+	}
+
+	thumbnails, err := imaging.Thumbnails(data)
+	if err != nil {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Format gambar harus JPEG atau PNG"})
+		return
+	}
+
+	image := models.ProductImage{
+		ProductID:   product.ID,
+		Key:         fmt.Sprintf("products/%d/%s%s", product.ID, uuid.New().String(), imaging.AllowedContentTypes[info.ContentType]),
+		ContentType: info.ContentType,
+		Size:        int64(len(data)),
+		Width:       info.Width,
+		Height:      info.Height,
+	}
+	wantPrimary := c.PostForm("is_primary") == "true"
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), storageTimeout)
+	defer cancel()
+
+	if err := putImageFiles(ctx, image, data, thumbnails); err != nil {
+		log.Printf("Gagal mengunggah gambar produk %d: %v", product.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan gambar"})
+		return
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		// Kunci baris produk agar upload bersamaan dihitung berurutan
+		var locked models.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&locked, product.ID).Error; err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&models.ProductImage{}).Where("product_id = ?", product.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count >= maxProductImages {
+			return errProductImageLimit
+		}
+		image.IsPrimary = wantPrimary || count == 0
+
+		var last int
+		if err := tx.Model(&models.ProductImage{}).Where("product_id = ?", product.ID).
+			Select("COALESCE(MAX(position), -1)").Scan(&last).Error; err != nil {
+			return err
+		}
+		image.Position = last + 1
+
+		if image.IsPrimary {
+			if err := clearPrimaryImage(tx, product.ID); err != nil {
+				return err
+			}
+		}
+		return tx.Create(&image).Error
+	})
+	if err != nil {
+		deleteImageFiles(ctx, image)
+		if errors.Is(err, errProductImageLimit) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Jumlah gambar produk sudah mencapai batas maksimal"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan gambar"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Gambar produk berhasil diunggah",
+		"image":   productImageResponse(image),
+	})
+}
+
+// ReorderProductImages mengatur urutan gambar produk dari daftar image_ids (admin only).
+// Daftar harus berisi semua gambar produk tersebut.
+func ReorderProductImages(c *gin.Context) {
+	product, ok := findProductParam(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		ImageIDs []uint `json:"image_ids" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	var images []models.ProductImage
+	if err := config.DB.Where("product_id = ?", product.ID).Find(&images).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data gambar"})
+		return
+	}
+
+	existing := make(map[uint]bool, len(images))
+	for _, image := range images {
+		existing[image.ID] = true
+	}
+	seen := make(map[uint]bool, len(input.ImageIDs))
+	for _, id := range input.ImageIDs {
+		if !existing[id] || seen[id] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "image_ids harus berisi setiap gambar produk tepat satu kali"})
+			return
+		}
+		seen[id] = true
+	}
+	if len(seen) != len(existing) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "image_ids harus berisi setiap gambar produk tepat satu kali"})
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		for position, id := range input.ImageIDs {
+			if err := tx.Model(&models.ProductImage{}).Where("id = ?", id).Update("position", position).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengubah urutan gambar"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Urutan gambar berhasil diubah"})
+}
+
+// SetPrimaryProductImage menjadikan gambar sebagai gambar utama produk (admin only)
+func SetPrimaryProductImage(c *gin.Context) {
+	product, ok := findProductParam(c)
+	if !ok {
+		return
+	}
+
+	image, ok := findProductImageParam(c, product.ID)
+	if !ok {
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := clearPrimaryImage(tx, product.ID); err != nil {
+			return err
+		}
+		return tx.Model(&image).Update("is_primary", true).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengubah gambar utama"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Gambar utama berhasil diubah"})
+}
+
+// DeleteProductImage menghapus gambar beserta thumbnail-nya (admin only). Jika gambar
+// utama dihapus, gambar berikutnya menurut urutan menjadi gambar utama.
+func DeleteProductImage(c *gin.Context) {
+	product, ok := findProductParam(c)
+	if !ok {
+		return
+	}
+
+	image, ok := findProductImageParam(c, product.ID)
+	if !ok {
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&image).Error; err != nil {
+			return err
+		}
+		if !image.IsPrimary {
+			return nil
+		}
+
+		var next models.ProductImage
+		err := tx.Where("product_id = ?", product.ID).Order("position").First(&next).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return tx.Model(&next).Update("is_primary", true).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus gambar"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), storageTimeout)
+	defer cancel()
+	deleteImageFiles(ctx, image)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Gambar berhasil dihapus"})
+}
+
+// putImageFiles mengunggah file asli dan thumbnail. Jika salah satu gagal,
+// file yang sudah terunggah dihapus kembali.
+func putImageFiles(ctx context.Context, image models.ProductImage, data []byte, thumbnails map[string][]byte) error {
+	if err := storage.Put(ctx, image.Key, data, image.ContentType); err != nil {
+		return err
+	}
+	for _, size := range imaging.ThumbnailSizes {
+		if err := storage.Put(ctx, image.ThumbnailKey(size.Name), thumbnails[size.Name], "image/jpeg"); err != nil {
+			deleteImageFiles(ctx, image)
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteImageFiles menghapus file asli dan thumbnail. Kegagalan hanya dicatat di log
+// karena data gambar di database sudah tidak merujuk ke file tersebut.
+func deleteImageFiles(ctx context.Context, image models.ProductImage) {
+	keys := []string{image.Key}
+	for _, size := range imaging.ThumbnailSizes {
+		keys = append(keys, image.ThumbnailKey(size.Name))
+	}
+	for _, key := range keys {
+		if err := storage.Delete(ctx, key); err != nil {
+			log.Printf("Gagal menghapus file %s: %v", key, err)
+		}
+	}
+}
+
+func clearPrimaryImage(tx *gorm.DB, productID uint) error {
+	return tx.Model(&models.ProductImage{}).
+		Where("product_id = ? AND is_primary = ?", productID, true).
+		Update("is_primary", false).Error
+}
+
+// fillImageURLs mengisi URL gambar dan thumbnail dari storage
+func fillImageURLs(images []models.ProductImage) {
+	for i := range images {
+		images[i].URL = storage.URL(images[i].Key)
+		images[i].Thumbnails = make(map[string]string, len(imaging.ThumbnailSizes))
+		for _, size := range imaging.ThumbnailSizes {
+			images[i].Thumbnails[size.Name] = storage.URL(images[i].ThumbnailKey(size.Name))
+		}
+	}
+}
+
+// findProductImageParam mengambil gambar produk dari parameter :imageId, atau menulis respons error
+func findProductImageParam(c *gin.Context, productID uint) (models.ProductImage, bool) {
+	var image models.ProductImage
+
+	id, err := strconv.Atoi(c.Param("imageId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID gambar tidak valid"})
+		return image, false
+	}
+
+	if err := config.DB.Where("id = ? AND product_id = ?", id, productID).First(&image).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Gambar tidak ditemukan"})
+		return image, false
+	}
+
+	return image, true
+}
+
+func productImageResponse(image models.ProductImage) gin.H {
+	images := []models.ProductImage{image}
+	fillImageURLs(images)
+
+	return gin.H{
+		"id":           image.ID,
+		"url":          images[0].URL,
+		"thumbnails":   images[0].Thumbnails,
+		"content_type": image.ContentType,
+		"size":         image.Size,
+		"width":        image.Width,
+		"height":       image.Height,
+		"position":     image.Position,
+		"is_primary":   image.IsPrimary,
+		"created_at":   image.CreatedAt,
+	}
+}
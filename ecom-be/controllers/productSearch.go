@@ -0,0 +1,148 @@
+package controllers
+
+import (
+	"ecom-be/config"
+	"ecom-be/models"
+	"ecom-be/search"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// maxSearchResults membatasi jumlah hasil pencarian full-text yang diteruskan
+// ke filter dan paginasi; jika hasilnya lebih banyak, respons menandai meta.truncated
+const maxSearchResults = 1000
+
+// searchBatchSize adalah jumlah produk yang dibaca per query saat membangun ulang index
+const searchBatchSize = 500
+
+// RebuildSearchIndex membangun ulang index pencarian dari semua produk di
+// database dan mengembalikan jumlah produk yang diindeks
+func RebuildSearchIndex() (int, error) {
+	count := 0
+	err := search.Default.Rebuild(func(put func(docs ...search.Document) error) error {
+		// Kategori dibaca setelah pembangunan dimulai agar perubahannya ikut disusulkan
+		categories, err := loadCategories()
+		if err != nil {
+			return err
+		}
+		paths := categoryPaths(categories)
+
+		var products []models.Product
+		return config.DB.Preload("Categories").FindInBatches(&products, searchBatchSize, func(tx *gorm.DB, batch int) error {
+			docs := make([]search.Document, 0, len(products))
+			for _, product := range products {
+				docs = append(docs, productSearchDocument(product, paths))
+			}
+			count += len(docs)
+			return put(docs...)
+		}).Error
+	})
+	return count, err
+}
+
+// StartSearchIndexWatch memakai index yang dibangun ulang proses lain (mis.
+// perintah reindex) dan menyusulkan produk yang berubah selama pembangunannya
+func StartSearchIndexWatch(interval time.Duration) {
+	search.Watch(interval, reindexProducts)
+}
+
+// reindexProducts memperbarui dokumen produk di index pencarian; produk yang
+// sudah tidak ada dihapus dari index. Index hanya salinan data di database,
+// jadi kegagalan cukup dicatat di log dan bisa diperbaiki dengan perintah reindex.
+func reindexProducts(ids []uint) {
+	if len(ids) == 0 {
+		return
+	}
+
+	categories, err := loadCategories()
+	var products []models.Product
+	if err == nil {
+		err = config.DB.Preload("Categories").Where("id IN ?", ids).Find(&products).Error
+	}
+	if err != nil {
+		log.Printf("Gagal memperbarui index pencarian: %v", err)
+		return
+	}
+
+	paths := categoryPaths(categories)
+	docs := make([]search.Document, 0, len(products))
+	found := make(map[uint]bool, len(products))
+	for _, product := range products {
+		docs = append(docs, productSearchDocument(product, paths))
+		found[product.ID] = true
+	}
+	if err := search.Put(docs...); err != nil {
+		log.Printf("Gagal memperbarui index pencarian: %v", err)
+	}
+
+	var missing []uint
+	for _, id := range ids {
+		if !found[id] {
+			missing = append(missing, id)
+		}
+	}
+	if err := search.Delete(missing...); err != nil {
+		log.Printf("Gagal menghapus produk dari index pencarian: %v", err)
+	}
+}
+
+// unindexProduct menghapus produk dari index pencarian
+func unindexProduct(id uint) {
+	if err := search.Delete(id); err != nil {
+		log.Printf("Gagal menghapus produk %d dari index pencarian: %v", id, err)
+	}
+}
+
+// categoryProductIDs mengembalikan ID produk yang terhubung ke kategori-kategori tertentu
+func categoryProductIDs(categoryIDs []uint) []uint {
+	var ids []uint
+	if err := config.DB.Table("product_categories").Where("category_id IN ?", categoryIDs).
+		Distinct().Pluck("product_id", &ids).Error; err != nil {
+		log.Printf("Gagal mengambil produk kategori: %v", err)
+	}
+	return ids
+}
+
+// categoryPaths memetakan ID kategori ke nama kategori tersebut beserta semua
+// induknya, agar produk di "Sepatu Lari" juga ditemukan dengan kata "Olahraga"
+func categoryPaths(categories []models.Category) map[uint][]string {
+	byID := make(map[uint]models.Category, len(categories))
+	for _, category := range categories {
+		byID[category.ID] = category
+	}
+
+	paths := make(map[uint][]string, len(categories))
+	for _, category := range categories {
+		// Dibatasi jumlah kategori untuk berjaga dari data yang melingkar
+		current, ok := category, true
+		for depth := 0; ok && depth < len(categories); depth++ {
+			paths[category.ID] = append(paths[category.ID], current.Name)
+			if current.ParentID == nil {
+				break
+			}
+			current, ok = byID[*current.ParentID]
+		}
+	}
+	return paths
+}
+
+func productSearchDocument(product models.Product, paths map[uint][]string) search.Document {
+	doc := search.Document{
+		ID:          product.ID,
+		Name:        product.Name,
+		Description: product.Description,
+	}
+
+	seen := map[string]bool{}
+	for _, category := range product.Categories {
+		for _, name := range paths[category.ID] {
+			if !seen[name] {
+				seen[name] = true
+				doc.Categories = append(doc.Categories, name)
+			}
+		}
+	}
+	return doc
+}
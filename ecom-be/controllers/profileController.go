@@ -0,0 +1,174 @@
+package controllers
+
+import (
+	"ecom-be/config"
+	"ecom-be/middleware"
+	"ecom-be/models"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// UpdateProfile mengubah nama dan/atau nomor telepon user
+func UpdateProfile(c *gin.Context) {
+	userClaims, _ := c.Get("user")
+	claims := userClaims.(*middleware.Claims)
+
+	var input struct {
+		Name  *string `json:"name" binding:"omitempty,min=2,max=100"`
+		Phone *string `json:"phone" binding:"omitempty,phone"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if len(name) < 2 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":  "Data yang dikirim tidak valid",
+				"fields": gin.H{"name": "minimal 2 karakter"},
+			})
+			return
+		}
+		updates["name"] = name
+	}
+	if input.Phone != nil {
+		updates["phone"] = *input.Phone
+	}
+
+	if len(updates) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Tidak ada data yang diubah"})
+		return
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, claims.UserID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User tidak ditemukan"})
+		return
+	}
+
+	if err := config.DB.Model(&user).Updates(updates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengubah profil"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Profil berhasil diubah",
+		"user": gin.H{
+			"id":    user.ID,
+			"name":  user.Name,
+			"email": user.Email,
+			"phone": user.Phone,
+		},
+	})
+}
+
+// UpdateEmail mengirim link verifikasi ke email baru; email baru aktif setelah diverifikasi
+func UpdateEmail(c *gin.Context) {
+	userClaims, _ := c.Get("user")
+	claims := userClaims.(*middleware.Claims)
+
+	var input struct {
+		Email    string `json:"email" binding:"required,email"`
+		Password string `json:"password" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, claims.UserID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User tidak ditemukan"})
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.Password)); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":  "Password tidak valid",
+			"fields": gin.H{"password": "password salah"},
+		})
+		return
+	}
+
+	if strings.EqualFold(input.Email, user.Email) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Data yang dikirim tidak valid",
+			"fields": gin.H{"email": "sama dengan email saat ini"},
+		})
+		return
+	}
+
+	var count int64
+	config.DB.Model(&models.User{}).Where("email = ?", input.Email).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":  "Email sudah terdaftar",
+			"fields": gin.H{"email": "sudah dipakai akun lain"},
+		})
+		return
+	}
+
+	if err := sendVerificationEmail(user, input.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengirim email verifikasi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Link verifikasi telah dikirim ke email baru. Email akan berubah setelah diverifikasi"})
+}
+
+// ChangePassword mengganti password dan mencabut token user di perangkat lain
+func ChangePassword(c *gin.Context) {
+	userClaims, _ := c.Get("user")
+	claims := userClaims.(*middleware.Claims)
+
+	var input struct {
+		CurrentPassword string `json:"current_password" binding:"required"`
+		NewPassword     string `json:"new_password" binding:"required,min=6,nefield=CurrentPassword"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, claims.UserID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User tidak ditemukan"})
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.CurrentPassword)); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":  "Password saat ini tidak valid",
+			"fields": gin.H{"current_password": "password salah"},
+		})
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengganti password"})
+		return
+	}
+
+	if err := config.DB.Model(&user).Update("password", string(hashedPassword)).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengganti password"})
+		return
+	}
+
+	// Sesi saat ini dipertahankan, sesi lain harus login ulang
+	if err := middleware.RevokeAllUserTokens(user.ID, claims.ID); err != nil {
+		log.Printf("Gagal mencabut sesi lain user %d: %v", user.ID, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password berhasil diganti"})
+}
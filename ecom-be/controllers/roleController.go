@@ -0,0 +1,215 @@
+package controllers
+
+import (
+	"ecom-be/config"
+	"ecom-be/middleware"
+	"ecom-be/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetPermissions menampilkan semua permission yang tersedia (admin only)
+func GetPermissions(c *gin.Context) {
+	var permissions []models.Permission
+	if err := config.DB.Order("name").Find(&permissions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data permission"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"permissions": permissions})
+}
+
+// GetRoles menampilkan semua role beserta permission-nya (admin only)
+func GetRoles(c *gin.Context) {
+	var roles []models.Role
+	if err := config.DB.Preload("Permissions").Order("name").Find(&roles).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data role"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"roles": roles})
+}
+
+// CreateRole membuat role baru (admin only)
+func CreateRole(c *gin.Context) {
+	var input struct {
+		Name             string   `json:"name" binding:"required,min=2,max=50"`
+		Description      string   `json:"description" binding:"max=255"`
+		Permissions      []string `json:"permissions"`
+		RequireTwoFactor bool     `json:"require_two_factor"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	var count int64
+	config.DB.Model(&models.Role{}).Where("name = ?", input.Name).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Role sudah ada"})
+		return
+	}
+
+	permissions, ok := findPermissions(c, input.Permissions)
+	if !ok {
+		return
+	}
+
+	role := models.Role{
+		Name:             input.Name,
+		Description:      input.Description,
+		Permissions:      permissions,
+		RequireTwoFactor: input.RequireTwoFactor,
+	}
+	if err := config.DB.Create(&role).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat role"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Role berhasil dibuat",
+		"role":    role,
+	})
+}
+
+// UpdateRole mengubah deskripsi, permission dan kewajiban 2FA role (admin only)
+func UpdateRole(c *gin.Context) {
+	var input struct {
+		Description      *string  `json:"description" binding:"omitempty,max=255"`
+		Permissions      []string `json:"permissions"`
+		RequireTwoFactor *bool    `json:"require_two_factor"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	role, ok := findRoleParam(c)
+	if !ok {
+		return
+	}
+
+	// Role admin selalu memiliki semua permission
+	if role.Name == models.RoleAdmin && input.Permissions != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Permission role admin tidak dapat diubah"})
+		return
+	}
+
+	permissions, ok := findPermissions(c, input.Permissions)
+	if !ok {
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if input.Description != nil {
+			if err := tx.Model(&role).Update("description", *input.Description).Error; err != nil {
+				return err
+			}
+		}
+		if input.RequireTwoFactor != nil {
+			if err := tx.Model(&role).Update("require_two_factor", *input.RequireTwoFactor).Error; err != nil {
+				return err
+			}
+		}
+		if input.Permissions != nil {
+			return tx.Model(&role).Association("Permissions").Replace(permissions)
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengubah role"})
+		return
+	}
+
+	middleware.InvalidatePermissionCache()
+	config.DB.Preload("Permissions").First(&role, role.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Role berhasil diubah",
+		"role":    role,
+	})
+}
+
+// DeleteRole menghapus role yang tidak dipakai user mana pun (admin only)
+func DeleteRole(c *gin.Context) {
+	role, ok := findRoleParam(c)
+	if !ok {
+		return
+	}
+
+	if models.IsDefaultRole(role.Name) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role bawaan tidak dapat dihapus"})
+		return
+	}
+
+	var count int64
+	config.DB.Model(&models.User{}).Where("role = ?", role.Name).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role masih dipakai oleh user"})
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&role).Association("Permissions").Clear(); err != nil {
+			return err
+		}
+		return tx.Delete(&role).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus role"})
+		return
+	}
+
+	middleware.InvalidatePermissionCache()
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role berhasil dihapus"})
+}
+
+// findRoleParam mengambil role berdasarkan parameter :id
+func findRoleParam(c *gin.Context) (models.Role, bool) {
+	var role models.Role
+
+	roleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID role tidak valid"})
+		return role, false
+	}
+
+	if err := config.DB.Preload("Permissions").First(&role, roleID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Role tidak ditemukan"})
+		return role, false
+	}
+
+	return role, true
+}
+
+// findPermissions mengubah daftar nama permission menjadi model, menolak nama yang tidak dikenal
+func findPermissions(c *gin.Context, names []string) ([]models.Permission, bool) {
+	permissions := []models.Permission{}
+	if len(names) == 0 {
+		return permissions, true
+	}
+
+	if err := config.DB.Where("name IN ?", names).Find(&permissions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data permission"})
+		return nil, false
+	}
+
+	found := make(map[string]bool, len(permissions))
+	for _, p := range permissions {
+		found[p.Name] = true
+	}
+	for _, name := range names {
+		if !found[name] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Permission tidak dikenal: " + name})
+			return nil, false
+		}
+	}
+
+	return permissions, true
+}
@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"ecom-be/config"
+	"ecom-be/middleware"
+	"ecom-be/models"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetSessions menampilkan sesi login user yang masih aktif di semua perangkat
+func GetSessions(c *gin.Context) {
+	userClaims, _ := c.Get("user")
+	claims := userClaims.(*middleware.Claims)
+
+	var sessions []models.Session
+	err := config.DB.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", claims.UserID, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data sesi"})
+		return
+	}
+
+	result := make([]gin.H, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, gin.H{
+			"id":           session.ID,
+			"user_agent":   session.UserAgent,
+			"ip":           session.IP,
+			"created_at":   session.CreatedAt,
+			"last_seen_at": session.LastSeenAt,
+			"current":      session.ID == claims.SessionID,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sessions": result})
+}
+
+// DeleteSession mencabut satu sesi, mis. untuk logout dari ponsel yang hilang
+func DeleteSession(c *gin.Context) {
+	userClaims, _ := c.Get("user")
+	claims := userClaims.(*middleware.Claims)
+
+	var session models.Session
+	if err := config.DB.Where("id = ? AND user_id = ?", c.Param("id"), claims.UserID).First(&session).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sesi tidak ditemukan"})
+		return
+	}
+
+	if err := middleware.RevokeSessionByID(session.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mencabut sesi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Sesi berhasil dicabut"})
+}
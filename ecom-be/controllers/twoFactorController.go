@@ -0,0 +1,360 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"ecom-be/config"
+	"ecom-be/middleware"
+	"ecom-be/models"
+	"ecom-be/throttle"
+	"ecom-be/totp"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// recoveryCodeCount adalah jumlah kode cadangan yang dibuat setiap kali
+const recoveryCodeCount = 10
+
+var errInvalidSecondFactor = errors.New("kode 2FA tidak valid")
+
+// SetupTwoFactor membuat secret TOTP baru dan mengembalikan URI untuk QR code.
+// 2FA belum aktif sampai dikonfirmasi melalui EnableTwoFactor.
+func SetupTwoFactor(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if user.IsTwoFactorEnabled() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "2FA sudah aktif"})
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat secret 2FA"})
+		return
+	}
+
+	if err := config.DB.Model(&user).Update("two_factor_secret", secret).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat secret 2FA"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"secret":           secret,
+		"provisioning_uri": totp.ProvisioningURI(os.Getenv("TOTP_ISSUER"), user.Email, secret),
+	})
+}
+
+// EnableTwoFactor mengaktifkan 2FA setelah user memasukkan kode dari aplikasi authenticator
+func EnableTwoFactor(c *gin.Context) {
+	var input struct {
+		Code string `json:"code" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if user.IsTwoFactorEnabled() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "2FA sudah aktif"})
+		return
+	}
+	if user.TwoFactorSecret == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Jalankan setup 2FA terlebih dahulu"})
+		return
+	}
+
+	step, valid := totp.Validate(user.TwoFactorSecret, input.Code, time.Now())
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Kode 2FA tidak valid"})
+		return
+	}
+
+	var codes []string
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"two_factor_enabled_at": now,
+			"two_factor_last_step":  step,
+		}).Error; err != nil {
+			return err
+		}
+
+		var err error
+		codes, err = replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengaktifkan 2FA"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "2FA berhasil diaktifkan. Simpan kode cadangan di tempat yang aman",
+		"recovery_codes": codes,
+	})
+}
+
+// DisableTwoFactor menonaktifkan 2FA dengan konfirmasi password dan kode 2FA
+func DisableTwoFactor(c *gin.Context) {
+	var input struct {
+		Password string `json:"password" binding:"required"`
+		Code     string `json:"code" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if !user.IsTwoFactorEnabled() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "2FA belum aktif"})
+		return
+	}
+
+	if required, err := middleware.RoleRequiresTwoFactor(user.Role); err != nil || required {
+		c.JSON(http.StatusForbidden, gin.H{"error": "2FA wajib untuk role Anda dan tidak dapat dinonaktifkan"})
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.Password)); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Password tidak valid"})
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := verifySecondFactor(tx, &user, input.Code); err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&user).Updates(map[string]interface{}{
+			"two_factor_secret":     "",
+			"two_factor_enabled_at": nil,
+			"two_factor_last_step":  0,
+		}).Error
+	})
+	if errors.Is(err, errInvalidSecondFactor) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Kode 2FA tidak valid"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menonaktifkan 2FA"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "2FA berhasil dinonaktifkan"})
+}
+
+// RegenerateRecoveryCodes mengganti semua kode cadangan dengan yang baru
+func RegenerateRecoveryCodes(c *gin.Context) {
+	var input struct {
+		Code string `json:"code" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if !user.IsTwoFactorEnabled() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "2FA belum aktif"})
+		return
+	}
+
+	var codes []string
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := verifySecondFactor(tx, &user, input.Code); err != nil {
+			return err
+		}
+		var err error
+		codes, err = replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if errors.Is(err, errInvalidSecondFactor) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Kode 2FA tidak valid"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat kode cadangan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// LoginTwoFactor menyelesaikan login dengan challenge token dan kode TOTP atau kode cadangan
+func LoginTwoFactor(c *gin.Context) {
+	var input struct {
+		ChallengeToken string `json:"challenge_token" binding:"required"`
+		Code           string `json:"code" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	challenge, err := middleware.ParseTwoFactorChallenge(input.ChallengeToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Challenge token tidak valid atau sudah kedaluwarsa"})
+		return
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, challenge.UserID).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Challenge token tidak valid atau sudah kedaluwarsa"})
+		return
+	}
+
+	if user.IsSuspended() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Akun Anda telah dinonaktifkan"})
+		return
+	}
+
+	// Kode 2FA hanya 6 digit, jadi percobaan dibatasi per user dan per IP
+	throttleKeys := map[string]throttle.Policy{
+		fmt.Sprintf("2fa:%d", user.ID): emailLoginPolicy,
+		"ip:" + c.ClientIP():           ipLoginPolicy,
+	}
+	if !checkThrottle(c, throttleKeys) {
+		recordLoginAttempt(c, user.Email, &user.ID, false, "locked")
+		return
+	}
+
+	// Challenge dipakai di transaksi yang sama sebelum kode dicek, sehingga dua
+	// request paralel dengan challenge yang sama tidak bisa sama-sama berhasil.
+	// Jika kode salah, transaksi di-rollback dan challenge masih bisa dicoba lagi.
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := middleware.ConsumeTwoFactorChallenge(tx, challenge); err != nil {
+			return err
+		}
+		return verifySecondFactor(tx, &user, input.Code)
+	})
+	if errors.Is(err, middleware.ErrChallengeInvalid) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Challenge token tidak valid atau sudah kedaluwarsa"})
+		return
+	}
+	if errors.Is(err, errInvalidSecondFactor) {
+		recordThrottleFailure(throttleKeys)
+		recordLoginAttempt(c, user.Email, &user.ID, false, "invalid_2fa")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Kode 2FA tidak valid"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memverifikasi kode 2FA"})
+		return
+	}
+
+	resetThrottle(fmt.Sprintf("2fa:%d", user.ID))
+	recordLoginAttempt(c, user.Email, &user.ID, true, "2fa")
+
+	issueLoginTokens(c, user, middleware.TokenOptions{MFA: true})
+}
+
+// verifySecondFactor mencocokkan kode TOTP (yang belum pernah dipakai) atau kode cadangan
+func verifySecondFactor(tx *gorm.DB, user *models.User, code string) error {
+	if !user.IsTwoFactorEnabled() {
+		return errInvalidSecondFactor
+	}
+
+	if step, ok := totp.Validate(user.TwoFactorSecret, code, time.Now()); ok {
+		// Kode TOTP yang sama tidak boleh dipakai dua kali
+		result := tx.Model(&models.User{}).
+			Where("id = ? AND two_factor_last_step < ?", user.ID, step).
+			Update("two_factor_last_step", step)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errInvalidSecondFactor
+		}
+		user.TwoFactorLastStep = step
+		return nil
+	}
+
+	result := tx.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.ID, middleware.HashToken(normalizeRecoveryCode(code))).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errInvalidSecondFactor
+	}
+	return nil
+}
+
+// replaceRecoveryCodes menghapus kode cadangan lama dan membuat yang baru
+func replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+	codes := make([]string, 0, recoveryCodeCount)
+	records := make([]models.RecoveryCode, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		raw := strings.ToLower(encoding.EncodeToString(b))[:10]
+		codes = append(codes, raw[:5]+"-"+raw[5:])
+		records = append(records, models.RecoveryCode{
+			UserID:   userID,
+			CodeHash: middleware.HashToken(raw),
+		})
+	}
+
+	if err := tx.Create(&records).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// currentUser mengambil user yang sedang login dari database
+func currentUser(c *gin.Context) (models.User, bool) {
+	userClaims, _ := c.Get("user")
+	claims := userClaims.(*middleware.Claims)
+
+	var user models.User
+	if err := config.DB.First(&user, claims.UserID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User tidak ditemukan"})
+		return user, false
+	}
+	return user, true
+}
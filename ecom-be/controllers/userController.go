@@ -4,7 +4,10 @@ import (
 	"ecom-be/config"
 	"ecom-be/middleware"
 	"ecom-be/models"
+	"log"
 	"net/http"
+	"sort"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
@@ -22,34 +25,43 @@ func Login(c *gin.Context) {
 		return
 	}
 
+	// Tolak sementara jika email atau IP sedang terkunci karena terlalu banyak gagal
+	throttleKeys := loginThrottleKeys(c, input.Email)
+	if !checkThrottle(c, throttleKeys) {
+		recordLoginAttempt(c, input.Email, nil, false, "locked")
+		return
+	}
+
 	var user models.User
 	if err := config.DB.Where("email = ?", input.Email).First(&user).Error; err != nil {
+		recordThrottleFailure(throttleKeys)
+		recordLoginAttempt(c, input.Email, nil, false, "unknown_email")
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Email atau password tidak valid"})
 		return
 	}
 
 	// Verifikasi password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.Password)); err != nil {
+		recordThrottleFailure(throttleKeys)
+		recordLoginAttempt(c, input.Email, &user.ID, false, "invalid_password")
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Email atau password tidak valid"})
 		return
 	}
 
-	// Generate JWT token
-	token, err := middleware.GenerateToken(user)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Tidak dapat membuat token"})
+	// Password benar: hitungan gagal untuk email ini direset
+	resetThrottle("email:" + strings.ToLower(strings.TrimSpace(input.Email)))
+
+	// Tolak akun yang dinonaktifkan admin
+	if user.IsSuspended() {
+		recordLoginAttempt(c, input.Email, &user.ID, false, "suspended")
+		c.JSON(http.StatusForbidden, gin.H{"error": "Akun Anda telah dinonaktifkan"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"token": token,
-		"user": gin.H{
-			"id":    user.ID,
-			"name":  user.Name,
-			"email": user.Email,
-			"role":  user.Role,
-		},
-	})
+	recordLoginAttempt(c, input.Email, &user.ID, true, "")
+
+	// Terbitkan token, atau challenge 2FA jika user mengaktifkannya
+	completeLogin(c, user)
 }
 
 // Register membuat akun user baru
@@ -92,7 +104,12 @@ func Register(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"message": "Registrasi berhasil"})
+	// Kirim email verifikasi; akun tetap dibuat meskipun pengiriman gagal
+	if err := sendVerificationEmail(user, user.Email); err != nil {
+		log.Printf("Gagal mengirim email verifikasi ke %s: %v", user.Email, err)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Registrasi berhasil, silakan cek email untuk verifikasi akun"})
 }
 
 // GetProfile menampilkan profil user
@@ -107,10 +124,22 @@ func GetProfile(c *gin.Context) {
 		return
 	}
 
+	// Daftar permission dipakai aplikasi untuk menampilkan menu admin
+	permissions := []string{}
+	if granted, err := middleware.RolePermissions(user.Role); err == nil {
+		for name := range granted {
+			permissions = append(permissions, name)
+		}
+		sort.Strings(permissions)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"id":    user.ID,
-		"name":  user.Name,
-		"email": user.Email,
-		"role":  user.Role,
+		"id":             user.ID,
+		"name":           user.Name,
+		"email":          user.Email,
+		"phone":          user.Phone,
+		"role":           user.Role,
+		"permissions":    permissions,
+		"email_verified": user.IsEmailVerified(),
 	})
 }
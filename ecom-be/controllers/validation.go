@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"ecom-be/middleware"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+var phonePattern = regexp.MustCompile(`^\+?[0-9]{8,15}$`)
+
+func init() {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		// Gunakan nama dari tag json agar pesan error sesuai dengan field di request
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+			if name == "" || name == "-" {
+				return field.Name
+			}
+			return name
+		})
+		v.RegisterValidation("phone", func(fl validator.FieldLevel) bool {
+			return phonePattern.MatchString(fl.Field().String())
+		})
+		v.RegisterValidation("appversion", func(fl validator.FieldLevel) bool {
+			_, err := middleware.ParseAppVersion(fl.Field().String())
+			return err == nil
+		})
+	}
+}
+
+// respondValidationError mengirim 400 dengan pesan error per field
+func respondValidationError(c *gin.Context, err error) {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	fields := make(map[string]string, len(validationErrors))
+	for _, fe := range validationErrors {
+		fields[fe.Field()] = validationMessage(fe)
+	}
+
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":  "Data yang dikirim tidak valid",
+		"fields": fields,
+	})
+}
+
+func validationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "wajib diisi"
+	case "email":
+		return "format email tidak valid"
+	case "phone":
+		return "nomor telepon harus berisi 8-15 digit angka"
+	case "min":
+		return fmt.Sprintf("minimal %s karakter", fe.Param())
+	case "max":
+		return fmt.Sprintf("maksimal %s karakter", fe.Param())
+	case "nefield":
+		return fmt.Sprintf("tidak boleh sama dengan %s", fe.Param())
+	case "appversion":
+		return "format versi harus seperti 1.2.3"
+	default:
+		return fmt.Sprintf("tidak memenuhi aturan %s", fe.Tag())
+	}
+}
@@ -0,0 +1,365 @@
+package controllers
+
+import (
+	"ecom-be/config"
+	"ecom-be/models"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type variantInput struct {
+	SKU     string            `json:"sku" binding:"required,max=64"`
+	Price   *float64          `json:"price" binding:"omitempty,gt=0"` // kosong berarti memakai harga produk
+	Stock   *int              `json:"stock" binding:"required,min=0"`
+	Options map[string]string `json:"options" binding:"required"`
+}
+
+// SetProductOptions mengatur tipe opsi varian produk, mis. ["size", "color"] (admin only).
+// Tipe opsi hanya bisa diubah selama produk belum memiliki varian.
+func SetProductOptions(c *gin.Context) {
+	product, ok := findProductParam(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Options []string `json:"options" binding:"required,dive,required,max=50"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	var variants int64
+	config.DB.Model(&models.ProductVariant{}).Where("product_id = ?", product.ID).Count(&variants)
+	if variants > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Hapus semua varian produk sebelum mengubah tipe opsi"})
+		return
+	}
+
+	options := make([]models.ProductOption, 0, len(input.Options))
+	seen := make(map[string]bool, len(input.Options))
+	for i, name := range input.Options {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if seen[name] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Tipe opsi tidak boleh duplikat: " + name})
+			return
+		}
+		seen[name] = true
+		options = append(options, models.ProductOption{ProductID: product.ID, Name: name, Position: i})
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("product_id = ?", product.ID).Delete(&models.ProductOption{}).Error; err != nil {
+			return err
+		}
+		if len(options) == 0 {
+			return nil
+		}
+		return tx.Create(&options).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan tipe opsi produk"})
+		return
+	}
+
+	names := make([]string, 0, len(options))
+	for _, option := range options {
+		names = append(names, option.Name)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Tipe opsi produk berhasil disimpan",
+		"options": names,
+	})
+}
+
+// CreateProductVariant menambahkan varian ke produk (admin only)
+func CreateProductVariant(c *gin.Context) {
+	product, ok := findProductParam(c)
+	if !ok {
+		return
+	}
+
+	var input variantInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	variant := models.ProductVariant{ProductID: product.ID}
+	if !input.apply(c, product, &variant) {
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&variant).Error; err != nil {
+			return err
+		}
+		return syncProductStock(tx, product.ID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan varian produk"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Varian produk berhasil dibuat",
+		"variant": variantResponse(variant, product),
+	})
+}
+
+// UpdateProductVariant mengubah SKU, harga, stok, atau opsi varian (admin only)
+func UpdateProductVariant(c *gin.Context) {
+	product, ok := findProductParam(c)
+	if !ok {
+		return
+	}
+
+	variant, ok := findVariantParam(c, product.ID)
+	if !ok {
+		return
+	}
+
+	var input variantInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	if !input.apply(c, product, &variant) {
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("variant_id = ?", variant.ID).Delete(&models.VariantOption{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Save(&variant).Error; err != nil {
+			return err
+		}
+		return syncProductStock(tx, product.ID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan varian produk"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Varian produk berhasil diubah",
+		"variant": variantResponse(variant, product),
+	})
+}
+
+// DeleteProductVariant menghapus varian beserta item keranjang yang memakainya (admin only).
+// Item pesanan lama tetap menyimpan SKU dan opsi varian.
+func DeleteProductVariant(c *gin.Context) {
+	product, ok := findProductParam(c)
+	if !ok {
+		return
+	}
+
+	variant, ok := findVariantParam(c, product.ID)
+	if !ok {
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("variant_id = ?", variant.ID).Delete(&models.CartItem{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("variant_id = ?", variant.ID).Delete(&models.VariantOption{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&variant).Error; err != nil {
+			return err
+		}
+		return syncProductStock(tx, product.ID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus varian produk"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Varian produk berhasil dihapus"})
+}
+
+// apply memvalidasi input terhadap tipe opsi produk lalu menyalinnya ke varian,
+// atau menulis respons error
+func (in variantInput) apply(c *gin.Context, product models.Product, variant *models.ProductVariant) bool {
+	var options []models.ProductOption
+	if err := config.DB.Where("product_id = ?", product.ID).Order("position").Find(&options).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil tipe opsi produk"})
+		return false
+	}
+	if len(options) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Atur tipe opsi produk terlebih dahulu"})
+		return false
+	}
+
+	// Setiap tipe opsi produk wajib diisi tepat satu nilai
+	values := make([]models.VariantOption, 0, len(options))
+	fields := gin.H{}
+	for _, option := range options {
+		value := strings.TrimSpace(in.Options[option.Name])
+		switch {
+		case value == "":
+			fields[option.Name] = "wajib diisi"
+		case len(value) > 100:
+			fields[option.Name] = "maksimal 100 karakter"
+		default:
+			values = append(values, models.VariantOption{Name: option.Name, Value: value})
+		}
+	}
+	for name := range in.Options {
+		if !containsOption(options, name) {
+			fields[name] = "bukan tipe opsi produk ini"
+		}
+	}
+	if len(fields) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Data yang dikirim tidak valid",
+			"fields": gin.H{"options": fields},
+		})
+		return false
+	}
+
+	sku := strings.TrimSpace(in.SKU)
+	var count int64
+	config.DB.Model(&models.ProductVariant{}).Where("sku = ? AND id <> ?", sku, variant.ID).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "SKU sudah dipakai"})
+		return false
+	}
+
+	// Kombinasi opsi tidak boleh sama dengan varian lain pada produk yang sama
+	candidate := models.ProductVariant{Options: values}
+	var existing []models.ProductVariant
+	if err := config.DB.Preload("Options").Where("product_id = ? AND id <> ?", product.ID, variant.ID).Find(&existing).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data varian"})
+		return false
+	}
+	for _, other := range existing {
+		if other.Label() == candidate.Label() {
+			c.JSON(http.StatusConflict, gin.H{"error": "Varian dengan kombinasi opsi ini sudah ada"})
+			return false
+		}
+	}
+
+	variant.SKU = sku
+	variant.Price = in.Price
+	variant.Stock = *in.Stock
+	variant.Options = values
+	return true
+}
+
+func containsOption(options []models.ProductOption, name string) bool {
+	for _, option := range options {
+		if option.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// findProductVariant mengambil varian yang dipilih untuk sebuah produk. Produk
+// yang memiliki varian wajib dipilih variannya; produk tanpa varian mengembalikan nil.
+func findProductVariant(db *gorm.DB, productID uint, variantID *uint) (*models.ProductVariant, error) {
+	if variantID == nil {
+		var count int64
+		if err := db.Model(&models.ProductVariant{}).Where("product_id = ?", productID).Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			return nil, errVariantRequired
+		}
+		return nil, nil
+	}
+
+	var variant models.ProductVariant
+	if err := db.Preload("Options").Where("id = ? AND product_id = ?", *variantID, productID).First(&variant).Error; err != nil {
+		return nil, errVariantNotFound
+	}
+	return &variant, nil
+}
+
+// adjustStock menambah atau mengurangi stok varian (jika ada) sekaligus total stok produknya
+func adjustStock(tx *gorm.DB, productID uint, variantID *uint, delta int) error {
+	if variantID != nil {
+		err := tx.Model(&models.ProductVariant{}).Where("id = ?", *variantID).
+			Update("stock", gorm.Expr("stock + ?", delta)).Error
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Model(&models.Product{}).Where("id = ?", productID).
+		Update("stock", gorm.Expr("stock + ?", delta)).Error
+}
+
+// syncProductStock menyamakan stok produk dengan total stok variannya
+func syncProductStock(tx *gorm.DB, productID uint) error {
+	var total int64
+	err := tx.Model(&models.ProductVariant{}).Where("product_id = ?", productID).
+		Select("COALESCE(SUM(stock), 0)").Scan(&total).Error
+	if err != nil {
+		return err
+	}
+	return tx.Model(&models.Product{}).Where("id = ?", productID).Update("stock", total).Error
+}
+
+// findProductParam mengambil produk dari parameter :id, atau menulis respons error
+func findProductParam(c *gin.Context) (models.Product, bool) {
+	var product models.Product
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID produk tidak valid"})
+		return product, false
+	}
+
+	if err := config.DB.First(&product, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Produk tidak ditemukan"})
+		return product, false
+	}
+
+	return product, true
+}
+
+// findVariantParam mengambil varian produk dari parameter :variantId, atau menulis respons error
+func findVariantParam(c *gin.Context, productID uint) (models.ProductVariant, bool) {
+	var variant models.ProductVariant
+
+	id, err := strconv.Atoi(c.Param("variantId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID varian tidak valid"})
+		return variant, false
+	}
+
+	if err := config.DB.Preload("Options").Where("id = ? AND product_id = ?", id, productID).First(&variant).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Varian produk tidak ditemukan"})
+		return variant, false
+	}
+
+	return variant, true
+}
+
+func variantResponse(variant models.ProductVariant, product models.Product) gin.H {
+	options := make(map[string]string, len(variant.Options))
+	for _, option := range variant.Options {
+		options[option.Name] = option.Value
+	}
+
+	return gin.H{
+		"id":             variant.ID,
+		"sku":            variant.SKU,
+		"price":          variant.PriceFor(product),
+		"price_override": variant.Price,
+		"stock":          variant.Stock,
+		"options":        options,
+	}
+}
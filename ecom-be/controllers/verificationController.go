@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"ecom-be/config"
+	"ecom-be/mailer"
+	"ecom-be/middleware"
+	"ecom-be/models"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var errEmailTaken = errors.New("email sudah terdaftar")
+
+// sendVerificationEmail membuat token verifikasi untuk email dan mengirimkannya
+func sendVerificationEmail(user models.User, email string) error {
+	rawToken, err := middleware.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	ttl := config.GetDuration("EMAIL_VERIFICATION_TTL", 24*time.Hour)
+	verification := models.EmailVerificationToken{
+		UserID:    user.ID,
+		Email:     email,
+		TokenHash: middleware.HashToken(rawToken),
+		ExpiresAt: time.Now().Add(ttl),
+	}
+
+	if err := config.DB.Create(&verification).Error; err != nil {
+		return err
+	}
+
+	return mailer.Send(mailer.Message{
+		To:      email,
+		Subject: "Verifikasi email Anda",
+		Body: fmt.Sprintf(
+			"Halo %s,\n\nSilakan verifikasi alamat email Anda dengan membuka link berikut:\n\n%s\n\n"+
+				"Link berlaku selama %s.\n",
+			user.Name, appLink("/verify-email", rawToken), ttl,
+		),
+	})
+}
+
+// VerifyEmail memverifikasi email user menggunakan token dari email
+func VerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token verifikasi wajib diisi"})
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var verification models.EmailVerificationToken
+		if err := tx.Where("token_hash = ? AND used_at IS NULL AND expires_at > ?",
+			middleware.HashToken(token), time.Now()).First(&verification).Error; err != nil {
+			return err
+		}
+
+		// Email bisa saja sudah dipakai akun lain sejak token dibuat
+		var count int64
+		tx.Model(&models.User{}).Where("email = ? AND id <> ?", verification.Email, verification.UserID).Count(&count)
+		if count > 0 {
+			return errEmailTaken
+		}
+
+		now := time.Now()
+		if err := tx.Model(&models.EmailVerificationToken{}).
+			Where("user_id = ? AND used_at IS NULL", verification.UserID).
+			Update("used_at", now).Error; err != nil {
+			return err
+		}
+
+		err := tx.Model(&models.User{}).Where("id = ?", verification.UserID).Updates(map[string]interface{}{
+			"email":             verification.Email,
+			"email_verified_at": now,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		// Email terbukti milik user: pesanan tamu dengan email ini masuk ke akunnya
+		return claimGuestOrders(tx, verification.UserID, verification.Email)
+	})
+
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token verifikasi tidak valid atau sudah kedaluwarsa"})
+	case errors.Is(err, errEmailTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": "Email sudah terdaftar"})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memverifikasi email"})
+	default:
+		c.JSON(http.StatusOK, gin.H{"message": "Email berhasil diverifikasi"})
+	}
+}
+
+// ResendVerification mengirim ulang email verifikasi untuk user yang sedang login
+func ResendVerification(c *gin.Context) {
+	userClaims, _ := c.Get("user")
+	claims := userClaims.(*middleware.Claims)
+
+	var user models.User
+	if err := config.DB.First(&user, claims.UserID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User tidak ditemukan"})
+		return
+	}
+
+	if user.IsEmailVerified() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Email sudah diverifikasi"})
+		return
+	}
+
+	// Batasi pengiriman ulang agar tidak dipakai untuk spam
+	var last models.EmailVerificationToken
+	if err := config.DB.Where("user_id = ?", user.ID).Order("created_at DESC").First(&last).Error; err == nil {
+		if wait := time.Minute - time.Since(last.CreatedAt); wait > 0 {
+			c.Header("Retry-After", fmt.Sprintf("%d", int(wait.Seconds())+1))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Tunggu sebentar sebelum meminta email verifikasi lagi"})
+			return
+		}
+	}
+
+	if err := sendVerificationEmail(user, user.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengirim email verifikasi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email verifikasi telah dikirim"})
+}
@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"ecom-be/config"
+	"ecom-be/middleware"
+	"ecom-be/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetWishlist menampilkan wishlist user beserta harga dan stok produk saat ini
+func GetWishlist(c *gin.Context) {
+	userClaims, _ := c.Get("user")
+	claims := userClaims.(*middleware.Claims)
+
+	var items []models.WishlistItem
+	if err := config.DB.Preload("Product").Where("user_id = ?", claims.UserID).Order("created_at DESC").Find(&items).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data wishlist"})
+		return
+	}
+
+	result := make([]gin.H, 0, len(items))
+	for _, item := range items {
+		result = append(result, gin.H{
+			"product_id": item.ProductID,
+			"name":       item.Product.Name,
+			"price":      item.Product.Price,
+			"stock":      item.Product.Stock,
+			"in_stock":   item.Product.Stock > 0,
+			"added_at":   item.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": result})
+}
+
+// AddToWishlist menyimpan produk ke wishlist. Produk yang sudah ada tidak diduplikasi.
+func AddToWishlist(c *gin.Context) {
+	userClaims, _ := c.Get("user")
+	claims := userClaims.(*middleware.Claims)
+
+	var input struct {
+		ProductID uint `json:"product_id" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	var product models.Product
+	if err := config.DB.First(&product, input.ProductID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Produk tidak ditemukan"})
+		return
+	}
+
+	item := models.WishlistItem{UserID: claims.UserID, ProductID: product.ID}
+	if err := config.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&item).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan wishlist"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Produk berhasil ditambahkan ke wishlist"})
+}
+
+// RemoveFromWishlist menghapus produk dari wishlist
+func RemoveFromWishlist(c *gin.Context) {
+	userClaims, _ := c.Get("user")
+	claims := userClaims.(*middleware.Claims)
+
+	item, ok := findWishlistParam(c, claims.UserID)
+	if !ok {
+		return
+	}
+
+	if err := config.DB.Delete(&item).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus produk dari wishlist"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Produk berhasil dihapus dari wishlist"})
+}
+
+// MoveWishlistToCart memindahkan produk dari wishlist ke keranjang dengan
+// pengecekan stok yang sama seperti AddToCart
+func MoveWishlistToCart(c *gin.Context) {
+	userClaims, _ := c.Get("user")
+	claims := userClaims.(*middleware.Claims)
+
+	var input struct {
+		VariantID *uint `json:"variant_id"` // wajib untuk produk yang memiliki varian
+		Quantity  int   `json:"quantity" binding:"omitempty,min=1"`
+	}
+
+	// Body opsional; tanpa body jumlahnya 1
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+	}
+	if input.Quantity == 0 {
+		input.Quantity = 1
+	}
+
+	item, ok := findWishlistParam(c, claims.UserID)
+	if !ok {
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := addProductToCart(tx, claims.UserID, item.ProductID, input.VariantID, input.Quantity); err != nil {
+			return err
+		}
+		return tx.Delete(&item).Error
+	})
+	if err != nil {
+		respondAddToCartError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Produk berhasil dipindahkan ke keranjang"})
+}
+
+// findWishlistParam mengambil item wishlist dari parameter :productId, atau menulis respons error
+func findWishlistParam(c *gin.Context, userID uint) (models.WishlistItem, bool) {
+	var item models.WishlistItem
+
+	productID, err := strconv.Atoi(c.Param("productId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID produk tidak valid"})
+		return item, false
+	}
+
+	if err := config.DB.Where("user_id = ? AND product_id = ?", userID, productID).First(&item).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Produk tidak ada di wishlist"})
+		return item, false
+	}
+
+	return item, true
+}
@@ -0,0 +1,152 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	_ "image/png" // daftarkan decoder PNG untuk image.Decode
+	"net/http"
+)
+
+// MaxPixels membatasi resolusi gambar yang mau didekode agar file kecil
+// dengan dimensi raksasa tidak menghabiskan memori. Gambar didekode utuh lalu
+// disalin ke RGBA, jadi 12 MP memakan sekitar 50 MB untuk salinan RGBA saja,
+// ditambah hasil decode-nya (hingga 96 MB untuk PNG 16-bit).
+const MaxPixels = 12_000_000
+
+// thumbnailQuality adalah kualitas JPEG untuk thumbnail
+const thumbnailQuality = 85
+
+// ThumbnailSize adalah ukuran thumbnail; gambar diperkecil agar sisi terpanjangnya MaxSide
+type ThumbnailSize struct {
+	Name    string
+	MaxSide int
+}
+
+// ThumbnailSizes adalah thumbnail yang dibuat untuk setiap gambar produk
+var ThumbnailSizes = []ThumbnailSize{
+	{Name: "small", MaxSide: 150},
+	{Name: "medium", MaxSide: 400},
+	{Name: "large", MaxSide: 800},
+}
+
+// AllowedContentTypes adalah format gambar yang diterima beserta ekstensi filenya
+var AllowedContentTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+}
+
+var (
+	ErrUnsupportedFormat = errors.New("format gambar tidak didukung")
+	ErrTooLarge          = errors.New("resolusi gambar terlalu besar")
+)
+
+// Info berisi hasil pemeriksaan file gambar
+type Info struct {
+	ContentType string
+	Width       int
+	Height      int
+}
+
+// Inspect mendeteksi format dari isi file (bukan dari header Content-Type
+// kiriman client) dan membaca dimensinya tanpa mendekode seluruh gambar
+func Inspect(data []byte) (Info, error) {
+	contentType := http.DetectContentType(data)
+	if _, ok := AllowedContentTypes[contentType]; !ok {
+		return Info{}, ErrUnsupportedFormat
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Info{}, ErrUnsupportedFormat
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return Info{}, ErrUnsupportedFormat
+	}
+	if cfg.Width*cfg.Height > MaxPixels {
+		return Info{}, ErrTooLarge
+	}
+
+	return Info{ContentType: contentType, Width: cfg.Width, Height: cfg.Height}, nil
+}
+
+// Thumbnails membuat thumbnail JPEG untuk setiap ukuran di ThumbnailSizes,
+// dikembalikan berdasarkan nama ukurannya
+func Thumbnails(data []byte) (map[string][]byte, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedFormat
+	}
+
+	// Ratakan ke RGBA di atas latar putih karena JPEG tidak mendukung transparansi
+	bounds := src.Bounds()
+	flat := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(flat, flat.Bounds(), &image.Uniform{C: color.White}, image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), src, bounds.Min, draw.Over)
+
+	result := make(map[string][]byte, len(ThumbnailSizes))
+	for _, size := range ThumbnailSizes {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, Fit(flat, size.MaxSide), &jpeg.Options{Quality: thumbnailQuality}); err != nil {
+			return nil, err
+		}
+		result[size.Name] = buf.Bytes()
+	}
+	return result, nil
+}
+
+// Fit memperkecil gambar agar sisi terpanjangnya maxSide dengan rasio tetap.
+// Gambar yang sudah lebih kecil tidak diperbesar.
+func Fit(src *image.RGBA, maxSide int) *image.RGBA {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	if w <= maxSide && h <= maxSide {
+		return src
+	}
+
+	dw, dh := maxSide, maxSide
+	if w > h {
+		dh = max(1, h*maxSide/w)
+	} else {
+		dw = max(1, w*maxSide/h)
+	}
+	return resize(src, dw, dh)
+}
+
+// resize memperkecil gambar dengan box filter: setiap piksel tujuan adalah
+// rata-rata piksel sumber yang tercakup olehnya
+func resize(src *image.RGBA, dw, dh int) *image.RGBA {
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < dh; y++ {
+		y0 := y * sh / dh
+		y1 := max(y0+1, (y+1)*sh/dh)
+		for x := 0; x < dw; x++ {
+			x0 := x * sw / dw
+			x1 := max(x0+1, (x+1)*sw/dw)
+
+			var r, g, b, a, n int
+			for sy := y0; sy < y1; sy++ {
+				i := src.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					r += int(src.Pix[i])
+					g += int(src.Pix[i+1])
+					b += int(src.Pix[i+2])
+					a += int(src.Pix[i+3])
+					i += 4
+					n++
+				}
+			}
+
+			j := dst.PixOffset(x, y)
+			dst.Pix[j] = uint8(r / n)
+			dst.Pix[j+1] = uint8(g / n)
+			dst.Pix[j+2] = uint8(b / n)
+			dst.Pix[j+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
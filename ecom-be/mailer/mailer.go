@@ -0,0 +1,98 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Message adalah email yang akan dikirim ke user
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer mengirimkan email melalui transport tertentu
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// Default adalah mailer yang dipakai aplikasi, diatur oleh Setup
+var Default Mailer = LogMailer{}
+
+// Setup memilih implementasi mailer berdasarkan MAIL_DRIVER (log, file, smtp)
+func Setup() {
+	switch strings.ToLower(os.Getenv("MAIL_DRIVER")) {
+	case "file":
+		Default = FileMailer{Dir: os.Getenv("MAIL_DIR")}
+	case "smtp":
+		Default = SMTPMailer{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     os.Getenv("SMTP_PORT"),
+			Username: os.Getenv("SMTP_USER"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("MAIL_FROM"),
+		}
+	default:
+		Default = LogMailer{}
+	}
+}
+
+// Send mengirim email melalui mailer default
+func Send(msg Message) error {
+	return Default.Send(msg)
+}
+
+// LogMailer hanya menulis email ke log, cocok untuk development
+type LogMailer struct{}
+
+func (LogMailer) Send(msg Message) error {
+	log.Printf("[mail] to=%s subject=%q\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// FileMailer menyimpan setiap email sebagai file .eml di Dir
+type FileMailer struct {
+	Dir string
+}
+
+func (m FileMailer) Send(msg Message) error {
+	dir := m.Dir
+	if dir == "" {
+		dir = "mail"
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), sanitizeFilename(msg.To))
+	return os.WriteFile(filepath.Join(dir, name), []byte(format(msg, "")), 0o644)
+}
+
+// format menyusun email dalam format RFC 822 sederhana
+func format(msg Message, from string) string {
+	var b strings.Builder
+	if from != "" {
+		fmt.Fprintf(&b, "From: %s\r\n", from)
+	}
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(msg.Body)
+	return b.String()
+}
+
+func sanitizeFilename(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || r == ' ' {
+			return '_'
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"ecom-be/config"
+	"ecom-be/models"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHeader adalah header tempat integrasi mengirim API key
+const APIKeyHeader = "X-API-Key"
+
+// apiKeyPrefix menandai kunci sebagai API key toko, memudahkan secret scanning
+const apiKeyPrefix = "ek_"
+
+// apiKeyTouchInterval membatasi seberapa sering LastUsedAt ditulis ke database
+const apiKeyTouchInterval = time.Minute
+
+// APIKeyPrincipal adalah identitas API key yang disimpan di context dengan kunci "api_key"
+type APIKeyPrincipal struct {
+	ID     uint
+	Name   string
+	Scopes map[string]bool
+}
+
+// GenerateAPIKey membuat API key baru. Yang disimpan di database hanya prefix
+// dan hash-nya; raw hanya ditampilkan sekali ke admin.
+func GenerateAPIKey() (raw, prefix string, err error) {
+	token, err := GenerateOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+	raw = apiKeyPrefix + token
+	return raw, raw[:len(apiKeyPrefix)+8], nil
+}
+
+// AuthRequiredOrAPIKey menerima X-API-Key sebagai alternatif Bearer JWT.
+// Akses per rute tetap dibatasi RequirePermission sesuai scope API key. Scope
+// dibatasi lagi oleh permission role pembuatnya saat ini, jadi kunci ikut
+// kehilangan akses jika pembuatnya dinonaktifkan, diturunkan, atau role-nya diubah.
+func AuthRequiredOrAPIKey() gin.HandlerFunc {
+	auth := AuthRequired()
+
+	return func(c *gin.Context) {
+		raw := c.GetHeader(APIKeyHeader)
+		if raw == "" {
+			auth(c)
+			return
+		}
+
+		var key models.APIKey
+		if err := config.DB.Preload("Scopes").Preload("CreatedBy").Where("key_hash = ?", HashToken(raw)).First(&key).Error; err != nil || key.RevokedAt != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+			c.Abort()
+			return
+		}
+		if !key.IsActive() {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "API key has expired"})
+			c.Abort()
+			return
+		}
+
+		if key.CreatedBy.IsSuspended() || key.CreatedBy.IsAnonymized() {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "API key owner is no longer active"})
+			c.Abort()
+			return
+		}
+
+		ownerPermissions, err := RolePermissions(key.CreatedBy.Role)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load permissions"})
+			c.Abort()
+			return
+		}
+
+		now := time.Now()
+		if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > apiKeyTouchInterval || key.LastUsedIP != c.ClientIP() {
+			// Gagal mencatat pemakaian tidak perlu menolak request
+			config.DB.Model(&key).Updates(map[string]interface{}{
+				"last_used_at": now,
+				"last_used_ip": c.ClientIP(),
+			})
+		}
+
+		principal := &APIKeyPrincipal{ID: key.ID, Name: key.Name, Scopes: map[string]bool{}}
+		for _, scope := range key.Scopes {
+			if ownerPermissions[scope.Name] {
+				principal.Scopes[scope.Name] = true
+			}
+		}
+		c.Set("api_key", principal)
+
+		c.Next()
+	}
+}
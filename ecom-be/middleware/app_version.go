@@ -0,0 +1,158 @@
+package middleware
+
+import (
+	"ecom-be/config"
+	"ecom-be/models"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Header yang dikirim aplikasi mobile di setiap request
+const (
+	AppVersionHeader  = "X-App-Version"
+	AppPlatformHeader = "X-App-Platform"
+)
+
+// appConfigCacheTTL menentukan berapa lama versi minimum disimpan di memori
+const appConfigCacheTTL = time.Minute
+
+var ErrInvalidAppVersion = errors.New("format versi aplikasi tidak valid")
+
+var (
+	platformCache          map[string]models.AppPlatform
+	platformCacheExpiresAt time.Time
+	platformCacheMu        sync.RWMutex
+)
+
+// ParseAppVersion mengubah versi seperti "2.10.1" menjadi angka per segmen (maksimal 4 segmen)
+func ParseAppVersion(version string) ([]int, error) {
+	parts := strings.Split(strings.TrimSpace(version), ".")
+	if len(parts) > 4 {
+		return nil, ErrInvalidAppVersion
+	}
+
+	segments := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, ErrInvalidAppVersion
+		}
+		segments[i] = n
+	}
+	return segments, nil
+}
+
+// CompareAppVersions mengembalikan -1, 0, atau 1 jika a lebih lama, sama, atau lebih baru dari b.
+// Segmen yang tidak ada dianggap 0, sehingga "2.1" sama dengan "2.1.0".
+func CompareAppVersions(a, b string) (int, error) {
+	va, err := ParseAppVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := ParseAppVersion(b)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := 0; i < len(va) || i < len(vb); i++ {
+		var x, y int
+		if i < len(va) {
+			x = va[i]
+		}
+		if i < len(vb) {
+			y = vb[i]
+		}
+		if x != y {
+			if x < y {
+				return -1, nil
+			}
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+// AppPlatforms mengembalikan pengaturan versi per platform, memakai cache bila masih berlaku
+func AppPlatforms() (map[string]models.AppPlatform, error) {
+	platformCacheMu.RLock()
+	cached, expiresAt := platformCache, platformCacheExpiresAt
+	platformCacheMu.RUnlock()
+	if cached != nil && time.Now().Before(expiresAt) {
+		return cached, nil
+	}
+
+	var rows []models.AppPlatform
+	if err := config.DB.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	platforms := make(map[string]models.AppPlatform, len(rows))
+	for _, row := range rows {
+		platforms[row.Platform] = row
+	}
+
+	platformCacheMu.Lock()
+	platformCache = platforms
+	platformCacheExpiresAt = time.Now().Add(appConfigCacheTTL)
+	platformCacheMu.Unlock()
+
+	return platforms, nil
+}
+
+// InvalidateAppConfigCache menghapus cache versi, dipanggil setelah admin mengubahnya
+func InvalidateAppConfigCache() {
+	platformCacheMu.Lock()
+	platformCache = nil
+	platformCacheMu.Unlock()
+}
+
+// RequireMinimumAppVersion menolak request dari build aplikasi di bawah versi
+// minimum platformnya dengan 426 Upgrade Required. Request tanpa header
+// X-App-Version (web, skrip, admin) tidak diperiksa.
+func RequireMinimumAppVersion() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		version := c.GetHeader(AppVersionHeader)
+		if version == "" {
+			c.Next()
+			return
+		}
+
+		platforms, err := AppPlatforms()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load app configuration"})
+			c.Abort()
+			return
+		}
+
+		platform, ok := platforms[strings.ToLower(c.GetHeader(AppPlatformHeader))]
+		if !ok {
+			c.Next()
+			return
+		}
+
+		cmp, err := CompareAppVersions(version, platform.MinVersion)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + AppVersionHeader + " header"})
+			c.Abort()
+			return
+		}
+		if cmp < 0 {
+			c.JSON(http.StatusUpgradeRequired, gin.H{
+				"error":       "App version is no longer supported",
+				"code":        "upgrade_required",
+				"min_version": platform.MinVersion,
+				"store_url":   platform.StoreURL,
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -1,9 +1,9 @@
 package middleware
 
 import (
+	"ecom-be/config"
 	"ecom-be/models"
 	"net/http"
-	"os"
 	"strings"
 	"time"
 
@@ -17,6 +17,10 @@ type Claims struct {
 	UserID uint   `json:"user_id"`
 	Email  string `json:"email"`
 	Role   string `json:"role"`
+	// MFA bernilai true jika login diverifikasi dengan 2FA
+	MFA bool `json:"mfa,omitempty"`
+	// SessionID adalah ID sesi login (models.Session) tempat token ini diterbitkan
+	SessionID string `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -42,9 +46,7 @@ func AuthRequired() gin.HandlerFunc {
 		tokenString := parts[1]
 
 		// Parsing token
-		token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-			return []byte(os.Getenv("JWT_SECRET")), nil
-		})
+		token, err := ParseToken(tokenString, &Claims{})
 
 		if err != nil || !token.Valid {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
@@ -52,62 +54,94 @@ func AuthRequired() gin.HandlerFunc {
 			return
 		}
 
-		// Set claims ke context untuk digunakan di rute lain
-		if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-			c.Set("user", claims)
+		// Access token tidak memiliki audience; token dengan audience
+		// (mis. challenge 2FA) tidak boleh dipakai untuk mengakses API
+		claims, ok := token.Claims.(*Claims)
+		if !ok || len(claims.Audience) > 0 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+			c.Abort()
+			return
 		}
 
-		c.Next()
-	}
-}
-
-// AdminRequired memastikan user adalah admin
-func AdminRequired() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// Pastikan sudah melalui AuthRequired
-		userClaims, exists := c.Get("user")
-		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		// Tolak token yang sudah dicabut (logout)
+		revoked, err := IsTokenRevoked(claims.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify token"})
 			c.Abort()
 			return
 		}
-
-		claims, ok := userClaims.(*Claims)
-		if !ok {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse user claims"})
+		if revoked {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token has been revoked"})
 			c.Abort()
 			return
 		}
 
-		// Cek apakah user memiliki role admin
-		if claims.Role != "admin" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+		// Tolak token dari sesi yang sudah dicabut (mis. perangkat hilang)
+		if claims.SessionID != "" {
+			active, err := touchSession(claims.SessionID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify session"})
+				c.Abort()
+				return
+			}
+			if !active {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked"})
+				c.Abort()
+				return
+			}
+		}
+
+		// Pastikan user masih ada, belum dihapus, dan tidak dinonaktifkan
+		var user models.User
+		err = config.DB.Select("id", "suspended_at", "anonymized_at").First(&user, claims.UserID).Error
+		if err != nil || user.IsAnonymized() {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+			c.Abort()
+			return
+		}
+		if user.IsSuspended() {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Account has been suspended"})
 			c.Abort()
 			return
 		}
 
+		// Set claims ke context untuk digunakan di rute lain
+		c.Set("user", claims)
+
 		c.Next()
 	}
 }
 
 // GenerateToken membuat token JWT baru untuk user
 func GenerateToken(user models.User) (string, error) {
-	expirationTime := time.Now().Add(24 * time.Hour) // Token berlaku 24 jam
-	
-	claims := &Claims{
+	return SignClaims(NewClaims(user, TokenOptions{}))
+}
+
+// NewClaims menyiapkan claim access token untuk user dengan jti baru
+func NewClaims(user models.User, opts TokenOptions) *Claims {
+	now := time.Now()
+	expirationTime := now.Add(AccessTokenTTL())
+
+	return &Claims{
 		UserID: user.ID,
 		Email:  user.Email,
 		Role:   user.Role,
+		MFA:    opts.MFA,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 			ID:        uuid.New().String(),
 		},
 	}
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+// SignClaims menandatangani claim menjadi token JWT dengan key aktif
+func SignClaims(claims jwt.Claims) (string, error) {
+	return signWithActiveKey(claims)
+}
 
-	return tokenString, err
+// ParseToken memverifikasi tanda tangan token (berdasarkan kid) dan mengisi claims
+func ParseToken(tokenString string, claims jwt.Claims) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(tokenString, claims, verificationKey)
 }
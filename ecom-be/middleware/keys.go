@@ -0,0 +1,290 @@
+package middleware
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// signingKey adalah satu key JWT beserta kid-nya. private kosong untuk key
+// yang hanya dipakai memverifikasi token lama (sudah dirotasi).
+type signingKey struct {
+	kid     string
+	method  jwt.SigningMethod
+	private interface{}
+	public  interface{}
+}
+
+type keyRing struct {
+	active *signingKey
+	keys   map[string]*signingKey
+}
+
+var (
+	keys   *keyRing
+	keysMu sync.RWMutex
+)
+
+// LoadSigningKeys memuat key JWT sesuai JWT_SIGNING_ALG:
+//
+//   - HS256 (default): memakai JWT_SECRET
+//   - RS256 / EdDSA: memuat semua key dari JWT_KEYS_DIR. File <kid>.pem berisi
+//     private key, file <kid>.pub.pem berisi public key lama yang masih diterima.
+//     JWT_ACTIVE_KID memilih key penanda tangan (default: kid terakhir secara urutan nama).
+func LoadSigningKeys() error {
+	ring, err := loadKeyRing()
+	if err != nil {
+		return err
+	}
+
+	keysMu.Lock()
+	keys = ring
+	keysMu.Unlock()
+	return nil
+}
+
+func loadKeyRing() (*keyRing, error) {
+	alg := strings.ToUpper(os.Getenv("JWT_SIGNING_ALG"))
+	switch alg {
+	case "", "HS256":
+		secret := os.Getenv("JWT_SECRET")
+		if secret == "" {
+			return nil, errors.New("JWT_SECRET wajib diisi untuk HS256")
+		}
+		key := &signingKey{method: jwt.SigningMethodHS256, private: []byte(secret), public: []byte(secret)}
+		// Token HS256 tidak memiliki kid
+		return &keyRing{active: key, keys: map[string]*signingKey{"": key}}, nil
+	case "RS256", "EDDSA":
+		return loadKeyDir(os.Getenv("JWT_KEYS_DIR"), os.Getenv("JWT_ACTIVE_KID"))
+	default:
+		return nil, fmt.Errorf("JWT_SIGNING_ALG %q tidak didukung", alg)
+	}
+}
+
+func loadKeyDir(dir, activeKid string) (*keyRing, error) {
+	if dir == "" {
+		return nil, errors.New("JWT_KEYS_DIR wajib diisi untuk RS256/EdDSA")
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+
+	ring := &keyRing{keys: map[string]*signingKey{}}
+	var signers []string
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		base := filepath.Base(file)
+		public := strings.HasSuffix(base, ".pub.pem")
+
+		var key *signingKey
+		if public {
+			key, err = parsePublicKey(data)
+		} else {
+			key, err = parsePrivateKey(data)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+
+		if public {
+			key.kid = strings.TrimSuffix(base, ".pub.pem")
+		} else {
+			key.kid = strings.TrimSuffix(base, ".pem")
+			signers = append(signers, key.kid)
+		}
+
+		// Private key menang jika kid yang sama juga punya file public
+		if existing, ok := ring.keys[key.kid]; ok && existing.private != nil {
+			continue
+		}
+		ring.keys[key.kid] = key
+	}
+
+	if len(signers) == 0 {
+		return nil, fmt.Errorf("tidak ada private key di %s", dir)
+	}
+
+	if activeKid == "" {
+		sort.Strings(signers)
+		activeKid = signers[len(signers)-1]
+	}
+	active, ok := ring.keys[activeKid]
+	if !ok || active.private == nil {
+		return nil, fmt.Errorf("private key untuk JWT_ACTIVE_KID %q tidak ditemukan", activeKid)
+	}
+	ring.active = active
+
+	return ring, nil
+}
+
+func parsePrivateKey(data []byte) (*signingKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("bukan file PEM")
+	}
+
+	var parsed interface{}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		if parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+			return nil, err
+		}
+	}
+
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		return &signingKey{method: jwt.SigningMethodRS256, private: k, public: &k.PublicKey}, nil
+	case ed25519.PrivateKey:
+		return &signingKey{method: jwt.SigningMethodEdDSA, private: k, public: k.Public()}, nil
+	default:
+		return nil, fmt.Errorf("tipe key %T tidak didukung", parsed)
+	}
+}
+
+func parsePublicKey(data []byte) (*signingKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("bukan file PEM")
+	}
+
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	switch k := parsed.(type) {
+	case *rsa.PublicKey:
+		return &signingKey{method: jwt.SigningMethodRS256, public: k}, nil
+	case ed25519.PublicKey:
+		return &signingKey{method: jwt.SigningMethodEdDSA, public: k}, nil
+	default:
+		return nil, fmt.Errorf("tipe key %T tidak didukung", parsed)
+	}
+}
+
+func currentKeys() (*keyRing, error) {
+	keysMu.RLock()
+	defer keysMu.RUnlock()
+
+	if keys == nil {
+		return nil, errors.New("signing key belum dimuat")
+	}
+	return keys, nil
+}
+
+// signWithActiveKey menandatangani claim dengan key aktif dan menambahkan header kid
+func signWithActiveKey(claims jwt.Claims) (string, error) {
+	ring, err := currentKeys()
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(ring.active.method, claims)
+	if ring.active.kid != "" {
+		token.Header["kid"] = ring.active.kid
+	}
+	return token.SignedString(ring.active.private)
+}
+
+// verificationKey memilih key berdasarkan kid dan memastikan algoritmanya cocok
+func verificationKey(token *jwt.Token) (interface{}, error) {
+	ring, err := currentKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := ring.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown kid %q", kid)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.public, nil
+}
+
+// PublicJWKs mengembalikan public key dalam format JWK untuk /.well-known/jwks.json.
+// Key HS256 tidak pernah dipublikasikan.
+func PublicJWKs() []map[string]string {
+	ring, err := currentKeys()
+	if err != nil {
+		return []map[string]string{}
+	}
+
+	kids := make([]string, 0, len(ring.keys))
+	for kid := range ring.keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	jwks := []map[string]string{}
+	for _, kid := range kids {
+		key := ring.keys[kid]
+		jwk := map[string]string{
+			"kid": kid,
+			"use": "sig",
+			"alg": key.method.Alg(),
+		}
+
+		switch pub := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk["kty"] = "RSA"
+			jwk["n"] = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk["e"] = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk["kty"] = "OKP"
+			jwk["crv"] = "Ed25519"
+			jwk["x"] = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+		jwks = append(jwks, jwk)
+	}
+	return jwks
+}
+
+// GeneratePrivateKeyPEM membuat private key baru (RS256 atau EdDSA) dalam format PKCS#8 PEM
+func GeneratePrivateKeyPEM(alg string) ([]byte, error) {
+	var private crypto.PrivateKey
+	var err error
+
+	switch strings.ToUpper(alg) {
+	case "RS256":
+		private, err = rsa.GenerateKey(rand.Reader, 2048)
+	case "EDDSA":
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("algoritma %q tidak didukung", alg)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"ecom-be/config"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// orderLookupAudience membedakan token pelacakan pesanan tamu dari access token
+const orderLookupAudience = "order-lookup"
+
+var ErrOrderLookupInvalid = errors.New("token pelacakan pesanan tidak valid")
+
+// OrderLookupTTL membaca umur token pelacakan pesanan tamu dari ORDER_LOOKUP_TTL (default 90 hari)
+func OrderLookupTTL() time.Duration {
+	return config.GetDuration("ORDER_LOOKUP_TTL", 90*24*time.Hour)
+}
+
+// GenerateOrderLookupToken membuat token bertanda tangan yang memberi akses ke satu pesanan tamu
+func GenerateOrderLookupToken(orderID uint) (string, error) {
+	now := time.Now()
+	claims := &jwt.RegisteredClaims{
+		Audience:  jwt.ClaimStrings{orderLookupAudience},
+		Subject:   strconv.FormatUint(uint64(orderID), 10),
+		ExpiresAt: jwt.NewNumericDate(now.Add(OrderLookupTTL())),
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+	}
+	return SignClaims(claims)
+}
+
+// ParseOrderLookupToken memverifikasi token pelacakan dan mengembalikan ID pesanannya
+func ParseOrderLookupToken(tokenString string) (uint, error) {
+	claims := &jwt.RegisteredClaims{}
+	token, err := ParseToken(tokenString, claims)
+	if err != nil || !token.Valid || !claims.VerifyAudience(orderLookupAudience, true) {
+		return 0, ErrOrderLookupInvalid
+	}
+
+	orderID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		return 0, ErrOrderLookupInvalid
+	}
+	return uint(orderID), nil
+}
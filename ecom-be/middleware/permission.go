@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"ecom-be/config"
+	"ecom-be/models"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// permissionCacheTTL menentukan berapa lama permission role disimpan di memori
+const permissionCacheTTL = time.Minute
+
+type cachedRole struct {
+	permissions      map[string]bool
+	requireTwoFactor bool
+	expiresAt        time.Time
+}
+
+var (
+	permissionCache   = map[string]cachedRole{}
+	permissionCacheMu sync.RWMutex
+)
+
+// loadRole mengambil permission dan kebijakan 2FA role, memakai cache bila masih berlaku
+func loadRole(name string) (cachedRole, error) {
+	permissionCacheMu.RLock()
+	cached, ok := permissionCache[name]
+	permissionCacheMu.RUnlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached, nil
+	}
+
+	var role models.Role
+	result := config.DB.Preload("Permissions").Where("name = ?", name).Limit(1).Find(&role)
+	if result.Error != nil {
+		return cachedRole{}, result.Error
+	}
+
+	cached = cachedRole{
+		permissions:      make(map[string]bool, len(role.Permissions)),
+		requireTwoFactor: role.RequireTwoFactor,
+		expiresAt:        time.Now().Add(permissionCacheTTL),
+	}
+	for _, p := range role.Permissions {
+		cached.permissions[p.Name] = true
+	}
+
+	permissionCacheMu.Lock()
+	permissionCache[name] = cached
+	permissionCacheMu.Unlock()
+
+	return cached, nil
+}
+
+// RolePermissions mengembalikan permission milik role
+func RolePermissions(role string) (map[string]bool, error) {
+	cached, err := loadRole(role)
+	return cached.permissions, err
+}
+
+// RoleRequiresTwoFactor mengecek apakah role mewajibkan login dengan 2FA
+func RoleRequiresTwoFactor(role string) (bool, error) {
+	cached, err := loadRole(role)
+	return cached.requireTwoFactor, err
+}
+
+// InvalidatePermissionCache menghapus cache permission, dipanggil setelah role diubah
+func InvalidatePermissionCache() {
+	permissionCacheMu.Lock()
+	permissionCache = map[string]cachedRole{}
+	permissionCacheMu.Unlock()
+}
+
+// RequirePermission memastikan role user, atau scope API key, memiliki permission tertentu
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Request dari API key hanya dibatasi oleh scope-nya
+		if value, exists := c.Get("api_key"); exists {
+			key, ok := value.(*APIKeyPrincipal)
+			if !ok || !key.Scopes[permission] {
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": "Scope required",
+					"scope": permission,
+				})
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+
+		// Pastikan sudah melalui AuthRequired
+		userClaims, exists := c.Get("user")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+
+		claims, ok := userClaims.(*Claims)
+		if !ok {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse user claims"})
+			c.Abort()
+			return
+		}
+
+		role, err := loadRole(claims.Role)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load permissions"})
+			c.Abort()
+			return
+		}
+
+		// Role yang mewajibkan 2FA hanya boleh memakai token hasil login 2FA
+		if role.requireTwoFactor && !claims.MFA {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Two-factor authentication required",
+				"code":  "two_factor_required",
+			})
+			c.Abort()
+			return
+		}
+
+		if !role.permissions[permission] {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":      "Permission required",
+				"permission": permission,
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"ecom-be/config"
+	"ecom-be/models"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RevokeToken memasukkan jti access token ke daftar pencabutan sampai token kedaluwarsa
+func RevokeToken(jti string, userID uint, expiresAt time.Time) error {
+	return revokeAccessTokens(config.DB, []models.RevokedToken{{
+		JTI:       jti,
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+	}})
+}
+
+// IsTokenRevoked mengecek apakah jti sudah dicabut
+func IsTokenRevoked(jti string) (bool, error) {
+	var count int64
+	err := config.DB.Model(&models.RevokedToken{}).
+		Where("jti = ? AND expires_at > ?", jti, time.Now()).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// RevokeSession mencabut access token dengan jti tersebut beserta refresh token family-nya
+func RevokeSession(claims *Claims) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		var record models.RefreshToken
+		err := tx.Where("jti = ?", claims.ID).First(&record).Error
+		if err == nil {
+			if err := revokeTokenFamily(tx, record.FamilyID); err != nil {
+				return err
+			}
+		}
+
+		return revokeAccessTokens(tx, []models.RevokedToken{{
+			JTI:       claims.ID,
+			UserID:    claims.UserID,
+			ExpiresAt: claims.ExpiresAt.Time,
+		}})
+	})
+}
+
+// RevokeAllUserTokens mencabut semua token milik user. Jika keepJTI diisi,
+// sesi (family) yang memiliki jti tersebut tetap dipertahankan.
+func RevokeAllUserTokens(userID uint, keepJTI string) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		query := tx.Where("user_id = ?", userID)
+
+		if keepJTI != "" {
+			var current models.RefreshToken
+			if err := tx.Where("jti = ?", keepJTI).First(&current).Error; err == nil {
+				query = query.Where("family_id <> ?", current.FamilyID)
+			} else {
+				query = query.Where("jti <> ?", keepJTI)
+			}
+		}
+
+		var records []models.RefreshToken
+		if err := query.Find(&records).Error; err != nil {
+			return err
+		}
+
+		return revokeRefreshRecords(tx, records)
+	})
+}
+
+func revokeTokenFamily(db *gorm.DB, familyID string) error {
+	var records []models.RefreshToken
+	if err := db.Where("family_id = ?", familyID).Find(&records).Error; err != nil {
+		return err
+	}
+	return revokeRefreshRecords(db, records)
+}
+
+// revokeRefreshRecords mencabut refresh token beserta access token pasangannya
+// yang masih berlaku
+func revokeRefreshRecords(db *gorm.DB, records []models.RefreshToken) error {
+	now := time.Now()
+	var ids []uint
+	var revoked []models.RevokedToken
+	families := map[string]bool{}
+
+	for _, record := range records {
+		families[record.FamilyID] = true
+		if record.RevokedAt == nil {
+			ids = append(ids, record.ID)
+		}
+		if record.AccessExpiresAt.After(now) {
+			revoked = append(revoked, models.RevokedToken{
+				JTI:       record.JTI,
+				UserID:    record.UserID,
+				ExpiresAt: record.AccessExpiresAt,
+			})
+		}
+	}
+
+	if len(ids) > 0 {
+		err := db.Model(&models.RefreshToken{}).
+			Where("id IN ?", ids).
+			Update("revoked_at", now).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	sessionIDs := make([]string, 0, len(families))
+	for family := range families {
+		sessionIDs = append(sessionIDs, family)
+	}
+	if err := markSessionsRevoked(db, sessionIDs); err != nil {
+		return err
+	}
+
+	return revokeAccessTokens(db, revoked)
+}
+
+func revokeAccessTokens(db *gorm.DB, tokens []models.RevokedToken) error {
+	if len(tokens) == 0 {
+		return nil
+	}
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&tokens).Error
+}
+
+// StartRevocationCleanup menghapus entri pencabutan yang token aslinya sudah
+// kedaluwarsa, beserta sesi yang sudah kedaluwarsa, secara berkala
+func StartRevocationCleanup(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			now := time.Now()
+			result := config.DB.Where("expires_at <= ?", now).Delete(&models.RevokedToken{})
+			if result.Error != nil {
+				log.Printf("Gagal membersihkan token yang dicabut: %v", result.Error)
+			}
+
+			result = config.DB.Where("expires_at <= ?", now).Delete(&models.Session{})
+			if result.Error != nil {
+				log.Printf("Gagal membersihkan sesi yang kedaluwarsa: %v", result.Error)
+			}
+		}
+	}()
+}
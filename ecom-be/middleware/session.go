@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"ecom-be/config"
+	"ecom-be/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// sessionTouchInterval membatasi seberapa sering LastSeenAt sesi ditulis ke database
+const sessionTouchInterval = time.Minute
+
+// RevokeSessionByID mencabut satu sesi login beserta semua token di dalamnya
+func RevokeSessionByID(sessionID string) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := revokeTokenFamily(tx, sessionID); err != nil {
+			return err
+		}
+		return markSessionsRevoked(tx, []string{sessionID})
+	})
+}
+
+func markSessionsRevoked(db *gorm.DB, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return db.Model(&models.Session{}).
+		Where("id IN ? AND revoked_at IS NULL", ids).
+		Update("revoked_at", time.Now()).Error
+}
+
+// touchSession mengecek apakah sesi masih aktif dan memperbarui waktu terakhir dipakai
+func touchSession(id string) (bool, error) {
+	var session models.Session
+	err := config.DB.Select("id", "revoked_at", "last_seen_at", "expires_at").Where("id = ?", id).First(&session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !session.IsActive() {
+		return false, nil
+	}
+
+	now := time.Now()
+	if now.Sub(session.LastSeenAt) > sessionTouchInterval {
+		// Gagal memperbarui LastSeenAt tidak perlu menolak request
+		config.DB.Model(&session).Update("last_seen_at", now)
+	}
+	return true, nil
+}
@@ -0,0 +1,205 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"ecom-be/config"
+	"ecom-be/models"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrRefreshTokenInvalid = errors.New("refresh token tidak valid")
+	ErrRefreshTokenExpired = errors.New("refresh token sudah kedaluwarsa")
+	ErrRefreshTokenReused  = errors.New("refresh token sudah pernah digunakan")
+	ErrAccountSuspended    = errors.New("akun dinonaktifkan")
+)
+
+// TokenOptions berisi informasi login yang ikut disimpan pada token
+type TokenOptions struct {
+	// MFA menandakan user sudah lolos verifikasi 2FA
+	MFA bool
+	// UserAgent dan IP perangkat yang login, dicatat pada sesi
+	UserAgent string
+	IP        string
+}
+
+// TokenPair berisi access token dan refresh token yang diterbitkan bersamaan
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	Claims       *Claims
+}
+
+// ExpiresIn mengembalikan sisa umur access token dalam detik
+func (p *TokenPair) ExpiresIn() int64 {
+	return int64(time.Until(p.Claims.ExpiresAt.Time).Seconds())
+}
+
+// AccessTokenTTL membaca umur access token dari ACCESS_TOKEN_TTL (default 15 menit)
+func AccessTokenTTL() time.Duration {
+	return config.GetDuration("ACCESS_TOKEN_TTL", 15*time.Minute)
+}
+
+// RefreshTokenTTL membaca umur refresh token dari REFRESH_TOKEN_TTL (default 30 hari)
+func RefreshTokenTTL() time.Duration {
+	return config.GetDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour)
+}
+
+// GenerateOpaqueToken membuat token acak yang aman untuk dikirim ke client
+func GenerateOpaqueToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken menghasilkan hash SHA-256 dari token untuk disimpan di database
+func HashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// IssueTokenPair menerbitkan access token dan refresh token untuk login baru
+// dan mencatat sesi baru untuk perangkat tersebut
+func IssueTokenPair(user models.User, opts TokenOptions) (*TokenPair, error) {
+	var pair *TokenPair
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		sessionID := uuid.New().String()
+		newPair, record, err := issueTokenPair(tx, user, sessionID, opts)
+		if err != nil {
+			return err
+		}
+
+		session := models.Session{
+			ID:         sessionID,
+			UserID:     user.ID,
+			JTI:        newPair.Claims.ID,
+			UserAgent:  opts.UserAgent,
+			IP:         opts.IP,
+			LastSeenAt: time.Now(),
+			ExpiresAt:  record.ExpiresAt,
+		}
+		if err := tx.Create(&session).Error; err != nil {
+			return err
+		}
+
+		pair = newPair
+		return nil
+	})
+	return pair, err
+}
+
+func issueTokenPair(db *gorm.DB, user models.User, familyID string, opts TokenOptions) (*TokenPair, *models.RefreshToken, error) {
+	claims := NewClaims(user, opts)
+	claims.SessionID = familyID
+	accessToken, err := SignClaims(claims)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rawRefresh, err := GenerateOpaqueToken()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	record := models.RefreshToken{
+		UserID:          user.ID,
+		TokenHash:       HashToken(rawRefresh),
+		FamilyID:        familyID,
+		JTI:             claims.ID,
+		AccessExpiresAt: claims.ExpiresAt.Time,
+		MFA:             opts.MFA,
+		ExpiresAt:       time.Now().Add(RefreshTokenTTL()),
+	}
+	if err := db.Create(&record).Error; err != nil {
+		return nil, nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: rawRefresh,
+		Claims:       claims,
+	}, &record, nil
+}
+
+// RotateRefreshToken menukar refresh token dengan pasangan token baru.
+// Refresh token yang sudah pernah dirotasi dianggap dicuri, sehingga seluruh
+// family-nya dicabut.
+func RotateRefreshToken(raw string) (*TokenPair, error) {
+	var pair *TokenPair
+	var reused bool
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var current models.RefreshToken
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", HashToken(raw)).
+			First(&current).Error
+		if err != nil {
+			return ErrRefreshTokenInvalid
+		}
+
+		if current.RevokedAt != nil {
+			// Token lama dipakai ulang: cabut seluruh family, commit tetap dijalankan
+			reused = true
+			return revokeTokenFamily(tx, current.FamilyID)
+		}
+
+		if time.Now().After(current.ExpiresAt) {
+			return ErrRefreshTokenExpired
+		}
+
+		var user models.User
+		if err := tx.First(&user, current.UserID).Error; err != nil {
+			return ErrRefreshTokenInvalid
+		}
+		if user.IsSuspended() {
+			return ErrAccountSuspended
+		}
+
+		newPair, record, err := issueTokenPair(tx, user, current.FamilyID, TokenOptions{MFA: current.MFA})
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		current.RevokedAt = &now
+		current.ReplacedByID = &record.ID
+		if err := tx.Save(&current).Error; err != nil {
+			return err
+		}
+
+		err = tx.Model(&models.Session{}).Where("id = ?", current.FamilyID).Updates(map[string]interface{}{
+			"jti":          newPair.Claims.ID,
+			"last_seen_at": now,
+			"expires_at":   record.ExpiresAt,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		pair = newPair
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	if reused {
+		return nil, ErrRefreshTokenReused
+	}
+	return pair, nil
+}
+
+// RevokeTokenFamily mencabut semua refresh token dalam satu family
+func RevokeTokenFamily(familyID string) error {
+	return revokeTokenFamily(config.DB, familyID)
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"ecom-be/models"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// twoFactorAudience membedakan challenge token dari access token
+const twoFactorAudience = "2fa-challenge"
+
+// TwoFactorChallengeTTL adalah umur challenge token setelah password terverifikasi
+const TwoFactorChallengeTTL = 5 * time.Minute
+
+var ErrChallengeInvalid = errors.New("challenge token tidak valid")
+
+// TwoFactorClaims adalah claim challenge token yang diterbitkan Login saat 2FA aktif
+type TwoFactorClaims struct {
+	UserID uint `json:"user_id"`
+	jwt.RegisteredClaims
+}
+
+// GenerateTwoFactorChallenge membuat challenge token berumur pendek untuk user
+func GenerateTwoFactorChallenge(userID uint) (string, error) {
+	now := time.Now()
+	claims := &TwoFactorClaims{
+		UserID: userID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{twoFactorAudience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(TwoFactorChallengeTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ID:        uuid.New().String(),
+		},
+	}
+	return SignClaims(claims)
+}
+
+// ParseTwoFactorChallenge memverifikasi challenge token yang belum dipakai
+func ParseTwoFactorChallenge(tokenString string) (*TwoFactorClaims, error) {
+	claims := &TwoFactorClaims{}
+	token, err := ParseToken(tokenString, claims)
+	if err != nil || !token.Valid || !claims.VerifyAudience(twoFactorAudience, true) {
+		return nil, ErrChallengeInvalid
+	}
+
+	revoked, err := IsTokenRevoked(claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrChallengeInvalid
+	}
+
+	return claims, nil
+}
+
+// ConsumeTwoFactorChallenge menandai challenge token sudah dipakai di dalam
+// transaksi tx. Request lain dengan challenge yang sama menunggu transaksi ini
+// selesai, lalu mendapat ErrChallengeInvalid jika transaksi di-commit.
+func ConsumeTwoFactorChallenge(tx *gorm.DB, claims *TwoFactorClaims) error {
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.RevokedToken{
+		JTI:       claims.ID,
+		UserID:    claims.UserID,
+		ExpiresAt: claims.ExpiresAt.Time,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrChallengeInvalid
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// AddressFields adalah isi alamat pengiriman. Struct ini juga disalin ke pesanan
+// sebagai snapshot agar perubahan buku alamat tidak mengubah pesanan lama.
+type AddressFields struct {
+	Recipient  string `gorm:"size:100"`
+	Phone      string `gorm:"size:20"`
+	Street     string `gorm:"size:255"`
+	District   string `gorm:"size:100"`
+	City       string `gorm:"size:100"`
+	Province   string `gorm:"size:100"`
+	PostalCode string `gorm:"size:10"`
+}
+
+// String menyusun alamat menjadi satu baris teks
+func (f AddressFields) String() string {
+	parts := []string{f.Recipient + " (" + f.Phone + ")", f.Street, f.District, f.City, f.Province + " " + f.PostalCode}
+	return strings.Join(parts, ", ")
+}
+
+// Address adalah alamat tersimpan di buku alamat user
+type Address struct {
+	ID            uint   `gorm:"primaryKey"`
+	UserID        uint   `gorm:"not null;index"`
+	User          User   `gorm:"foreignKey:UserID"`
+	Label         string `gorm:"size:50"` // mis. "Rumah", "Kantor"
+	AddressFields `gorm:"embedded"`
+	IsDefault     bool `gorm:"not null;default:false"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// APIKey adalah kunci untuk integrasi server-ke-server (ERP, skrip gudang).
+// Hanya hash-nya yang disimpan; Scopes memakai nama permission yang sama dengan role.
+type APIKey struct {
+	ID          uint         `gorm:"primaryKey"`
+	Name        string       `gorm:"size:100;not null"`
+	Prefix      string       `gorm:"size:16;not null"` // awal kunci untuk membantu identifikasi
+	KeyHash     string       `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	Scopes      []Permission `gorm:"many2many:api_key_scopes"`
+	CreatedByID uint         `gorm:"not null;index"`
+	CreatedBy   User         `gorm:"foreignKey:CreatedByID"`
+	ExpiresAt   *time.Time
+	LastUsedAt  *time.Time
+	LastUsedIP  string `gorm:"size:45"`
+	RevokedAt   *time.Time
+	CreatedAt   time.Time
+}
+
+// NonDelegableScopes berisi permission yang tidak boleh diberikan ke API key
+// karena bisa dipakai untuk menaikkan hak akses
+var NonDelegableScopes = map[string]bool{
+	PermUsersWrite:    true,
+	PermRolesManage:   true,
+	PermAPIKeysManage: true,
+}
+
+// IsActive mengecek apakah API key belum dicabut dan belum kedaluwarsa
+func (k APIKey) IsActive() bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || time.Now().Before(*k.ExpiresAt)
+}
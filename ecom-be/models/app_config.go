@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// AppPlatform menyimpan versi aplikasi mobile yang didukung untuk satu platform
+type AppPlatform struct {
+	Platform      string `gorm:"primaryKey;size:10"` // android atau ios
+	MinVersion    string `gorm:"size:20;not null"`   // build di bawah versi ini wajib update
+	LatestVersion string `gorm:"size:20;not null"`
+	StoreURL      string `gorm:"size:255"`
+	UpdatedAt     time.Time
+}
+
+// Level pesan aplikasi
+const (
+	AppMessageInfo        = "info"
+	AppMessageWarning     = "warning"
+	AppMessageMaintenance = "maintenance"
+)
+
+// AppMessage adalah pengumuman yang ditampilkan aplikasi, mis. jadwal maintenance.
+// Platform kosong berarti untuk semua platform.
+type AppMessage struct {
+	ID        uint   `gorm:"primaryKey"`
+	Title     string `gorm:"size:100;not null"`
+	Body      string `gorm:"type:text"`
+	Level     string `gorm:"size:20;not null;default:'info'"`
+	Platform  string `gorm:"size:10"`
+	StartsAt  *time.Time
+	EndsAt    *time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// IsActiveAt mengecek apakah pesan sedang berlaku pada waktu t
+func (m AppMessage) IsActiveAt(t time.Time) bool {
+	if m.StartsAt != nil && t.Before(*m.StartsAt) {
+		return false
+	}
+	return m.EndsAt == nil || t.Before(*m.EndsAt)
+}
+
+// FeatureFlag mengaktifkan atau menonaktifkan fitur di aplikasi tanpa rilis baru
+type FeatureFlag struct {
+	Key         string `gorm:"primaryKey;size:100"`
+	Enabled     bool   `gorm:"not null;default:false"`
+	Description string `gorm:"size:255"`
+	UpdatedAt   time.Time
+}
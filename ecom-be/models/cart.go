@@ -3,20 +3,31 @@ package models
 import "time"
 
 type Cart struct {
-	ID        uint       `gorm:"primaryKey"`
-	UserID    uint       `gorm:"not null;uniqueIndex"`
-	User      User       `gorm:"foreignKey:UserID"`
-	CartItems []CartItem `gorm:"foreignKey:CartID"`
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID             uint       `gorm:"primaryKey"`
+	UserID         *uint      `gorm:"uniqueIndex"` // kosong untuk keranjang tamu
+	User           User       `gorm:"foreignKey:UserID"`
+	GuestTokenHash *string    `gorm:"size:64;uniqueIndex" json:"-"` // hash token keranjang tamu
+	CartItems      []CartItem `gorm:"foreignKey:CartID"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
 }
 
 type CartItem struct {
-	ID        uint    `gorm:"primaryKey"`
-	CartID    uint    `gorm:"not null"`
-	ProductID uint    `gorm:"not null"`
-	Product   Product `gorm:"foreignKey:ProductID"`
-	Quantity  int     `gorm:"not null;default:1"`
+	ID        uint            `gorm:"primaryKey"`
+	CartID    uint            `gorm:"not null"`
+	ProductID uint            `gorm:"not null"`
+	Product   Product         `gorm:"foreignKey:ProductID"`
+	VariantID *uint           `gorm:"index"` // wajib untuk produk yang memiliki varian
+	Variant   *ProductVariant `gorm:"foreignKey:VariantID"`
+	Quantity  int             `gorm:"not null;default:1"`
 	CreatedAt time.Time
 	UpdatedAt time.Time
-} 
\ No newline at end of file
+}
+
+// UnitPrice mengembalikan harga satuan item; Product dan Variant harus sudah di-preload
+func (item CartItem) UnitPrice() float64 {
+	if item.Variant != nil {
+		return item.Variant.PriceFor(item.Product)
+	}
+	return item.Product.Price
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Category adalah node pada pohon kategori produk. ParentID kosong berarti kategori utama.
+type Category struct {
+	ID        uint   `gorm:"primaryKey"`
+	ParentID  *uint  `gorm:"index"`
+	Name      string `gorm:"size:100;not null"`
+	Slug      string `gorm:"size:120;not null;uniqueIndex"`
+	Icon      string `gorm:"size:255"`
+	SortOrder int    `gorm:"not null;default:0"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// DescendantIDs mengembalikan ID kategori root beserta semua turunannya dari daftar kategori
+func DescendantIDs(categories []Category, root uint) []uint {
+	children := make(map[uint][]uint, len(categories))
+	for _, category := range categories {
+		if category.ParentID != nil {
+			children[*category.ParentID] = append(children[*category.ParentID], category.ID)
+		}
+	}
+
+	ids := []uint{root}
+	seen := map[uint]bool{root: true}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range children[ids[i]] {
+			if !seen[child] {
+				seen[child] = true
+				ids = append(ids, child)
+			}
+		}
+	}
+	return ids
+}
@@ -0,0 +1,9 @@
+package models
+
+import "time"
+
+// DataMigration mencatat migrasi data satu kali yang sudah dijalankan
+type DataMigration struct {
+	Name      string `gorm:"primaryKey;size:100"`
+	AppliedAt time.Time
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// Device adalah perangkat mobile yang terdaftar untuk menerima push notification.
+// Token FCM/APNs unik; jika perangkat berpindah akun, token dipindahkan ke user baru.
+type Device struct {
+	ID         uint   `gorm:"primaryKey"`
+	UserID     uint   `gorm:"not null;index"`
+	User       User   `gorm:"foreignKey:UserID"`
+	Platform   string `gorm:"size:10;not null"`
+	Token      string `gorm:"size:255;not null;uniqueIndex"`
+	LastSeenAt time.Time
+	CreatedAt  time.Time
+}
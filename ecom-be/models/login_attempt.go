@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// LoginAttempt mencatat setiap percobaan login untuk ditinjau admin
+type LoginAttempt struct {
+	ID        uint      `gorm:"primaryKey"`
+	Email     string    `gorm:"size:191;index"`
+	UserID    *uint     `gorm:"index"`
+	IP        string    `gorm:"size:45;index"`
+	UserAgent string    `gorm:"size:255"`
+	Success   bool      `gorm:"not null"`
+	Reason    string    `gorm:"size:50"`
+	CreatedAt time.Time `gorm:"index"`
+}
+
+// LoginThrottle menyimpan status percobaan gagal per kunci (email/IP) agar
+// bisa dibagi antar instance server
+type LoginThrottle struct {
+	Key           string `gorm:"primaryKey;size:191"`
+	Failures      int    `gorm:"not null;default:0"`
+	LockedUntil   *time.Time
+	LastFailureAt time.Time
+}
@@ -14,22 +14,29 @@ const (
 
 type Order struct {
 	ID            uint         `gorm:"primaryKey"`
-	UserID        uint         `gorm:"not null"`
+	UserID        *uint        `gorm:"index"` // kosong untuk pesanan tamu
 	User          User         `gorm:"foreignKey:UserID"`
 	OrderItems    []OrderItem  `gorm:"foreignKey:OrderID"`
 	TotalAmount   float64      `gorm:"not null"`
 	Status        OrderStatus  `gorm:"type:varchar(20);default:'pending'"`
 	ShippingAddress string     `gorm:"type:text;not null"`
+	ShippingDetail AddressFields `gorm:"embedded;embeddedPrefix:shipping_"` // snapshot alamat dari buku alamat
 	PaymentMethod string       `gorm:"type:varchar(50);not null"`
+	GuestName     string       `gorm:"size:100"`
+	GuestEmail    string       `gorm:"size:191;index"` // pesanan tamu diklaim ke akun dengan email ini setelah diverifikasi
+	GuestPhone    string       `gorm:"size:20"`
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
 }
 
 type OrderItem struct {
-	ID        uint    `gorm:"primaryKey"`
-	OrderID   uint    `gorm:"not null"`
-	ProductID uint    `gorm:"not null"`
-	Product   Product `gorm:"foreignKey:ProductID"`
-	Quantity  int     `gorm:"not null"`
-	Price     float64 `gorm:"not null"`
+	ID           uint    `gorm:"primaryKey"`
+	OrderID      uint    `gorm:"not null"`
+	ProductID    uint    `gorm:"not null"`
+	Product      Product `gorm:"foreignKey:ProductID"`
+	VariantID    *uint   `gorm:"index"`
+	SKU          string  `gorm:"size:64"`  // snapshot SKU varian saat checkout
+	VariantLabel string  `gorm:"size:255"` // snapshot opsi varian, mis. "color: Merah, size: M"
+	Quantity     int     `gorm:"not null"`
+	Price        float64 `gorm:"not null"`
 } 
\ No newline at end of file
@@ -2,11 +2,17 @@ package models
 
 import "time"
 
+// Product adalah barang yang dijual. Untuk produk yang memiliki varian, Stock
+// adalah total stok semua varian dan Price menjadi harga dasar varian.
 type Product struct {
-    ID          uint      `gorm:"primaryKey"`
-    Name        string    `gorm:"size:255;not null"`
-    Description string    `gorm:"type:text"`
-    Price       float64   `gorm:"not null"`
-    Stock       int       `gorm:"not null"`
+    ID          uint             `gorm:"primaryKey"`
+    Name        string           `gorm:"size:255;not null"`
+    Description string           `gorm:"type:text"`
+    Price       float64          `gorm:"not null"`
+    Stock       int              `gorm:"not null"`
+    Categories  []Category       `gorm:"many2many:product_categories" json:",omitempty"`
+    Options     []ProductOption  `gorm:"foreignKey:ProductID" json:",omitempty"`
+    Variants    []ProductVariant `gorm:"foreignKey:ProductID" json:",omitempty"`
+    Images      []ProductImage   `gorm:"foreignKey:ProductID" json:",omitempty"`
     CreatedAt   time.Time
 }
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"path"
+	"strings"
+	"time"
+)
+
+// ProductImage adalah gambar produk. File asli dan thumbnail-nya disimpan di
+// storage; yang disimpan di database hanya key file asli.
+type ProductImage struct {
+	ID          uint   `gorm:"primaryKey"`
+	ProductID   uint   `gorm:"not null;index"`
+	Key         string `gorm:"size:255;not null" json:"-"`
+	ContentType string `gorm:"size:50;not null"`
+	Size        int64  `gorm:"not null"`
+	Width       int    `gorm:"not null"`
+	Height      int    `gorm:"not null"`
+	Position    int    `gorm:"not null;default:0"`
+	IsPrimary   bool   `gorm:"not null;default:false"`
+	CreatedAt   time.Time
+
+	// URL diisi controller dari storage, tidak disimpan di database
+	URL        string            `gorm:"-"`
+	Thumbnails map[string]string `gorm:"-"`
+}
+
+// ThumbnailKey mengembalikan key thumbnail untuk ukuran tertentu,
+// mis. products/1/abc.png menjadi products/1/abc_small.jpg
+func (img ProductImage) ThumbnailKey(size string) string {
+	return strings.TrimSuffix(img.Key, path.Ext(img.Key)) + "_" + size + ".jpg"
+}
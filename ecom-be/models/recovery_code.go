@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// RecoveryCode adalah kode cadangan sekali pakai untuk login 2FA
+type RecoveryCode struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"not null;index"`
+	CodeHash  string `gorm:"size:64;not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// RefreshToken menyimpan hash refresh token yang diterbitkan bersama access token.
+// Semua token hasil rotasi dari satu login berbagi FamilyID yang sama.
+type RefreshToken struct {
+	ID              uint   `gorm:"primaryKey"`
+	UserID          uint   `gorm:"not null;index"`
+	User            User   `gorm:"foreignKey:UserID"`
+	TokenHash       string `gorm:"size:64;not null;uniqueIndex"`
+	FamilyID        string `gorm:"size:36;not null;index"`
+	JTI             string `gorm:"size:36;not null;index"` // jti access token pasangannya
+	AccessExpiresAt time.Time
+	MFA             bool      // login diverifikasi dengan 2FA
+	ExpiresAt       time.Time `gorm:"not null"`
+	RevokedAt       *time.Time
+	ReplacedByID    *uint
+	CreatedAt       time.Time
+}
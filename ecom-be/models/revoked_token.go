@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// RevokedToken mencatat jti access token yang dicabut sebelum masa berlakunya habis.
+// Baris dihapus otomatis setelah ExpiresAt terlewati.
+type RevokedToken struct {
+	JTI       string    `gorm:"primaryKey;size:36"`
+	UserID    uint      `gorm:"not null;index"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	CreatedAt time.Time
+}
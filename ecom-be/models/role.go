@@ -0,0 +1,67 @@
+package models
+
+import "time"
+
+// Nama permission yang dipakai oleh rute admin
+const (
+	PermProductsWrite   = "products:write"
+	PermProductsDelete  = "products:delete"
+	PermOrdersRead      = "orders:read"
+	PermOrdersUpdate    = "orders:update"
+	PermUsersRead       = "users:read"
+	PermUsersWrite      = "users:write"
+	PermRolesManage     = "roles:manage"
+	PermAPIKeysManage   = "api_keys:manage"
+	PermAppConfigManage = "app_config:manage"
+)
+
+// Nama role bawaan
+const (
+	RoleAdmin     = "admin"
+	RoleWarehouse = "warehouse"
+	RoleUser      = "user"
+)
+
+type Permission struct {
+	ID          uint   `gorm:"primaryKey"`
+	Name        string `gorm:"size:100;not null;uniqueIndex"`
+	Description string `gorm:"size:255"`
+}
+
+type Role struct {
+	ID          uint         `gorm:"primaryKey"`
+	Name        string       `gorm:"size:50;not null;uniqueIndex"`
+	Description string       `gorm:"size:255"`
+	Permissions []Permission `gorm:"many2many:role_permissions"`
+	// RequireTwoFactor mewajibkan user dengan role ini login menggunakan 2FA
+	RequireTwoFactor bool `gorm:"not null;default:false"`
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+// DefaultPermissions berisi semua permission yang dikenal aplikasi beserta deskripsinya
+var DefaultPermissions = []Permission{
+	{Name: PermProductsWrite, Description: "Membuat dan mengubah produk"},
+	{Name: PermProductsDelete, Description: "Menghapus produk"},
+	{Name: PermOrdersRead, Description: "Melihat semua pesanan"},
+	{Name: PermOrdersUpdate, Description: "Mengubah status pesanan"},
+	{Name: PermUsersRead, Description: "Melihat data user"},
+	{Name: PermUsersWrite, Description: "Mengubah role dan status akun user"},
+	{Name: PermRolesManage, Description: "Mengelola role dan permission"},
+	{Name: PermAPIKeysManage, Description: "Membuat dan mencabut API key integrasi"},
+	{Name: PermAppConfigManage, Description: "Mengatur versi minimum, pengumuman, dan fitur aplikasi mobile"},
+}
+
+// DefaultRolePermissions berisi role bawaan dan permission awalnya.
+// Role admin selalu mendapat semua permission.
+var DefaultRolePermissions = map[string][]string{
+	RoleAdmin:     nil,
+	RoleWarehouse: {PermOrdersRead, PermOrdersUpdate},
+	RoleUser:      {},
+}
+
+// IsDefaultRole mengecek apakah role termasuk role bawaan yang tidak boleh dihapus
+func IsDefaultRole(name string) bool {
+	_, ok := DefaultRolePermissions[name]
+	return ok
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Session adalah satu sesi login di satu perangkat. ID sesi sama dengan FamilyID
+// refresh token, sehingga sesi tetap sama walaupun token dirotasi.
+type Session struct {
+	ID         string `gorm:"primaryKey;size:36"`
+	UserID     uint   `gorm:"not null;index"`
+	User       User   `gorm:"foreignKey:UserID"`
+	JTI        string `gorm:"size:36;not null"` // jti access token terbaru di sesi ini
+	UserAgent  string `gorm:"size:255"`
+	IP         string `gorm:"size:45"`
+	LastSeenAt time.Time
+	ExpiresAt  time.Time `gorm:"not null;index"` // mengikuti masa berlaku refresh token terbaru
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+}
+
+// IsActive mengecek apakah sesi belum dicabut dan belum kedaluwarsa
+func (s Session) IsActive() bool {
+	return s.RevokedAt == nil && time.Now().Before(s.ExpiresAt)
+}
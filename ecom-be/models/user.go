@@ -3,10 +3,38 @@ package models
 import "time"
 
 type User struct {
-    ID        uint      `gorm:"primaryKey"`
-    Name      string    `gorm:"size:100;not null"`
-    Email     string    `gorm:"unique;not null"`
-    Password  string    `gorm:"not null"`
-    Role      string    `gorm:"default:'user'"`
-    CreatedAt time.Time
+    ID                 uint   `gorm:"primaryKey"`
+    Name               string `gorm:"size:100;not null"`
+    Email              string `gorm:"unique;not null"`
+    Password           string `gorm:"not null" json:"-"`
+    Phone              string `gorm:"size:20"`
+    Role               string `gorm:"default:'user'"`
+    EmailVerifiedAt    *time.Time
+    SuspendedAt        *time.Time
+    SuspensionReason   string `gorm:"size:255"`
+    TwoFactorSecret    string `gorm:"size:64" json:"-"`
+    TwoFactorEnabledAt *time.Time
+    TwoFactorLastStep  int64      `json:"-"` // periode TOTP terakhir yang dipakai, mencegah kode dipakai ulang
+    AnonymizedAt       *time.Time // akun dihapus user; data pribadi sudah dianonimkan
+    CreatedAt          time.Time
+}
+
+// IsEmailVerified mengecek apakah email user sudah diverifikasi
+func (u User) IsEmailVerified() bool {
+    return u.EmailVerifiedAt != nil
+}
+
+// IsTwoFactorEnabled mengecek apakah user sudah mengaktifkan 2FA
+func (u User) IsTwoFactorEnabled() bool {
+    return u.TwoFactorEnabledAt != nil
+}
+
+// IsSuspended mengecek apakah akun user sedang dinonaktifkan admin
+func (u User) IsSuspended() bool {
+    return u.SuspendedAt != nil
+}
+
+// IsAnonymized mengecek apakah akun sudah dihapus dan dianonimkan
+func (u User) IsAnonymized() bool {
+    return u.AnonymizedAt != nil
 }
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// UserIdentity menghubungkan user dengan akun di penyedia OpenID Connect (Google, Apple, ...)
+type UserIdentity struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"not null;index"`
+	User      User   `gorm:"foreignKey:UserID"`
+	Provider  string `gorm:"size:50;not null;uniqueIndex:idx_provider_subject"`
+	Subject   string `gorm:"size:191;not null;uniqueIndex:idx_provider_subject"`
+	Email     string `gorm:"size:191"`
+	CreatedAt time.Time
+}
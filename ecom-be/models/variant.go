@@ -0,0 +1,53 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// ProductOption adalah tipe opsi yang dimiliki varian sebuah produk, mis. size atau color
+type ProductOption struct {
+	ID        uint   `gorm:"primaryKey"`
+	ProductID uint   `gorm:"not null;uniqueIndex:idx_product_option"`
+	Name      string `gorm:"size:50;not null;uniqueIndex:idx_product_option"`
+	Position  int    `gorm:"not null;default:0"`
+}
+
+// ProductVariant adalah satu kombinasi opsi produk dengan SKU, harga, dan stok sendiri
+type ProductVariant struct {
+	ID        uint            `gorm:"primaryKey"`
+	ProductID uint            `gorm:"not null;index"`
+	SKU       string          `gorm:"size:64;not null;uniqueIndex"`
+	Price     *float64        // kosong berarti memakai harga produk
+	Stock     int             `gorm:"not null;default:0"`
+	Options   []VariantOption `gorm:"foreignKey:VariantID"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// VariantOption menyimpan nilai satu tipe opsi untuk sebuah varian, mis. size = M
+type VariantOption struct {
+	ID        uint   `gorm:"primaryKey"`
+	VariantID uint   `gorm:"not null;uniqueIndex:idx_variant_option"`
+	Name      string `gorm:"size:50;not null;uniqueIndex:idx_variant_option;index:idx_option_value"`
+	Value     string `gorm:"size:100;not null;index:idx_option_value"`
+}
+
+// PriceFor mengembalikan harga varian, atau harga produk jika varian tidak menimpanya
+func (v ProductVariant) PriceFor(product Product) float64 {
+	if v.Price != nil {
+		return *v.Price
+	}
+	return product.Price
+}
+
+// Label menyusun opsi varian menjadi teks seperti "color: Merah, size: M"
+func (v ProductVariant) Label() string {
+	parts := make([]string, 0, len(v.Options))
+	for _, option := range v.Options {
+		parts = append(parts, option.Name+": "+option.Value)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ", ")
+}
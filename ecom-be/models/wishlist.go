@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// WishlistItem adalah produk yang disimpan user untuk dibeli nanti
+type WishlistItem struct {
+	ID        uint    `gorm:"primaryKey"`
+	UserID    uint    `gorm:"not null;uniqueIndex:idx_wishlist_user_product"`
+	User      User    `gorm:"foreignKey:UserID"`
+	ProductID uint    `gorm:"not null;uniqueIndex:idx_wishlist_user_product"`
+	Product   Product `gorm:"foreignKey:ProductID"`
+	CreatedAt time.Time
+}
@@ -0,0 +1,147 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+)
+
+const (
+	// jwksCacheTTL adalah lama key disimpan sebelum diambil ulang
+	jwksCacheTTL = time.Hour
+	// jwksMinRefresh membatasi refresh saat kid tidak dikenal agar tidak membanjiri penyedia
+	jwksMinRefresh = time.Minute
+)
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// keySet adalah cache public key dari JWKS penyedia
+type keySet struct {
+	provider  *Provider
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+// get mengembalikan key untuk kid; JWKS diambil ulang jika cache kedaluwarsa
+// atau kid belum dikenal (penyedia baru saja merotasi key)
+func (s *keySet) get(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	age := time.Since(s.fetchedAt)
+	key, ok := s.keys[kid]
+	if ok && age < jwksCacheTTL {
+		return key, nil
+	}
+
+	if !ok && s.keys != nil && age < jwksMinRefresh {
+		return nil, fmt.Errorf("kid %q tidak dikenal", kid)
+	}
+
+	if err := s.refresh(ctx); err != nil {
+		// Pakai key lama jika penyedia sedang tidak bisa dihubungi
+		if ok {
+			return key, nil
+		}
+		return nil, err
+	}
+
+	key, ok = s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("kid %q tidak dikenal", kid)
+	}
+	return key, nil
+}
+
+func (s *keySet) refresh(ctx context.Context) error {
+	url, err := s.provider.jwksURL(ctx)
+	if err != nil {
+		return err
+	}
+
+	var doc struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := getJSON(ctx, url, &doc); err != nil {
+		return err
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(doc.Keys))
+	for _, jwk := range doc.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+
+	s.keys = keys
+	s.fetchedAt = time.Now()
+	return nil
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("curve %q tidak didukung", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("kty %q tidak didukung", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,224 @@
+// Package oidc memverifikasi ID token dari penyedia OpenID Connect
+// (mis. Google dan Apple) menggunakan JWKS yang di-cache.
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+var (
+	ErrUnknownProvider = errors.New("penyedia OIDC tidak dikenal")
+	ErrInvalidToken    = errors.New("ID token tidak valid")
+)
+
+// Provider adalah konfigurasi satu penyedia OIDC
+type Provider struct {
+	Name string
+	// Issuers berisi nilai iss yang diterima (Google memakai dua bentuk)
+	Issuers []string
+	// ClientIDs berisi audience yang diterima, mis. client ID Android, iOS dan web
+	ClientIDs []string
+	// JWKSURL opsional; jika kosong diambil dari discovery document issuer
+	JWKSURL string
+
+	keys *keySet
+}
+
+// Identity adalah data user yang sudah diverifikasi dari ID token
+type Identity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// idTokenClaims adalah claim ID token yang dipakai aplikasi
+type idTokenClaims struct {
+	Email         string   `json:"email"`
+	EmailVerified flexBool `json:"email_verified"`
+	Name          string   `json:"name"`
+	Nonce         string   `json:"nonce"`
+	jwt.RegisteredClaims
+}
+
+// flexBool menerima true maupun "true" (Apple mengirim email_verified sebagai string)
+type flexBool bool
+
+func (b *flexBool) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	*b = flexBool(s == "true")
+	return nil
+}
+
+// defaults berisi konfigurasi bawaan untuk penyedia yang umum dipakai
+var defaults = map[string]Provider{
+	"google": {
+		Issuers: []string{"https://accounts.google.com", "accounts.google.com"},
+		JWKSURL: "https://www.googleapis.com/oauth2/v3/certs",
+	},
+	"apple": {
+		Issuers: []string{"https://appleid.apple.com"},
+		JWKSURL: "https://appleid.apple.com/auth/keys",
+	},
+}
+
+var (
+	providers  = map[string]*Provider{}
+	httpClient = &http.Client{Timeout: 10 * time.Second}
+)
+
+// Setup membaca penyedia dari OIDC_PROVIDERS (mis. "google,apple"). Untuk tiap
+// penyedia NAME dibaca OIDC_NAME_CLIENT_IDS, OIDC_NAME_ISSUER dan OIDC_NAME_JWKS_URL.
+// Penyedia tanpa client ID dilewati.
+func Setup() {
+	providers = map[string]*Provider{}
+
+	for _, name := range splitList(os.Getenv("OIDC_PROVIDERS")) {
+		name = strings.ToLower(name)
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+
+		p := defaults[name]
+		p.Name = name
+		p.ClientIDs = splitList(os.Getenv(prefix + "CLIENT_IDS"))
+		if issuer := os.Getenv(prefix + "ISSUER"); issuer != "" {
+			p.Issuers = splitList(issuer)
+		}
+		if jwksURL := os.Getenv(prefix + "JWKS_URL"); jwksURL != "" {
+			p.JWKSURL = jwksURL
+		}
+
+		if len(p.ClientIDs) == 0 || len(p.Issuers) == 0 {
+			continue
+		}
+		Register(&p)
+	}
+}
+
+// Register menambahkan atau mengganti penyedia, berguna untuk issuer stub saat testing
+func Register(p *Provider) {
+	p.keys = &keySet{provider: p}
+	providers[p.Name] = p
+}
+
+// Lookup mengembalikan penyedia berdasarkan nama
+func Lookup(name string) (*Provider, error) {
+	p, ok := providers[strings.ToLower(name)]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return p, nil
+}
+
+// Verify memverifikasi tanda tangan, issuer, audience, masa berlaku dan nonce ID token
+func (p *Provider) Verify(ctx context.Context, rawToken, nonce string) (*Identity, error) {
+	claims := &idTokenClaims{}
+	token, err := jwt.ParseWithClaims(rawToken, claims, func(token *jwt.Token) (interface{}, error) {
+		switch token.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA:
+		default:
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		kid, _ := token.Header["kid"].(string)
+		return p.keys.get(ctx, kid)
+	})
+	if err != nil || !token.Valid {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	if !contains(p.Issuers, claims.Issuer) {
+		return nil, fmt.Errorf("%w: issuer %q", ErrInvalidToken, claims.Issuer)
+	}
+
+	audienceOK := false
+	for _, aud := range claims.Audience {
+		if contains(p.ClientIDs, aud) {
+			audienceOK = true
+			break
+		}
+	}
+	if !audienceOK {
+		return nil, fmt.Errorf("%w: audience", ErrInvalidToken)
+	}
+
+	if claims.ExpiresAt == nil || claims.Subject == "" {
+		return nil, fmt.Errorf("%w: exp/sub", ErrInvalidToken)
+	}
+
+	if nonce != "" && claims.Nonce != nonce {
+		return nil, fmt.Errorf("%w: nonce", ErrInvalidToken)
+	}
+
+	return &Identity{
+		Subject:       claims.Subject,
+		Email:         strings.ToLower(claims.Email),
+		EmailVerified: bool(claims.EmailVerified),
+		Name:          claims.Name,
+	}, nil
+}
+
+// jwksURL mengembalikan URL JWKS, mengambilnya dari discovery document bila perlu
+func (p *Provider) jwksURL(ctx context.Context) (string, error) {
+	if p.JWKSURL != "" {
+		return p.JWKSURL, nil
+	}
+
+	discovery := strings.TrimRight(p.Issuers[0], "/") + "/.well-known/openid-configuration"
+	var doc struct {
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := getJSON(ctx, discovery, &doc); err != nil {
+		return "", err
+	}
+	if doc.JWKSURI == "" {
+		return "", fmt.Errorf("discovery document %s tidak berisi jwks_uri", discovery)
+	}
+
+	p.JWKSURL = doc.JWKSURI
+	return p.JWKSURL, nil
+}
+
+func getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: status %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package push
+
+import (
+	"context"
+	"sync"
+)
+
+// Delivery adalah satu push notification yang diterima FakeSender
+type Delivery struct {
+	Device  Device
+	Message Message
+}
+
+// FakeSender menyimpan push notification di memori untuk testing. Token yang
+// didaftarkan lewat MarkInvalid akan ditolak dengan ErrInvalidToken.
+type FakeSender struct {
+	mu      sync.Mutex
+	sent    []Delivery
+	invalid map[string]bool
+}
+
+// NewFakeSender membuat FakeSender kosong
+func NewFakeSender() *FakeSender {
+	return &FakeSender{invalid: map[string]bool{}}
+}
+
+// MarkInvalid membuat pengiriman ke token tersebut gagal seperti token yang sudah mati
+func (f *FakeSender) MarkInvalid(tokens ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, token := range tokens {
+		f.invalid[token] = true
+	}
+}
+
+func (f *FakeSender) Send(ctx context.Context, device Device, msg Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.invalid[device.Token] {
+		return ErrInvalidToken
+	}
+	f.sent = append(f.sent, Delivery{Device: device, Message: msg})
+	return nil
+}
+
+// Sent mengembalikan salinan semua push notification yang berhasil dikirim
+func (f *FakeSender) Sent() []Delivery {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Delivery(nil), f.sent...)
+}
+
+// Reset menghapus riwayat pengiriman
+func (f *FakeSender) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = nil
+}
@@ -0,0 +1,224 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+var fcmClient = &http.Client{Timeout: 10 * time.Second}
+
+// fcmScope adalah scope OAuth2 untuk mengirim pesan lewat FCM
+const fcmScope = "https://www.googleapis.com/auth/firebase.messaging"
+
+// FCMSender mengirim push notification lewat Firebase Cloud Messaging HTTP v1.
+// FCM meneruskan notifikasi ke APNs untuk perangkat iOS, jadi aplikasi iOS juga
+// mendaftarkan token FCM. Access token OAuth2 dibuat dari service account dan
+// disimpan sampai hampir kedaluwarsa.
+type FCMSender struct {
+	ProjectID   string
+	ClientEmail string
+	PrivateKey  *rsa.PrivateKey
+	TokenURL    string // endpoint token OAuth2 Google
+	Endpoint    string // base URL API FCM
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// serviceAccount adalah bagian file kredensial service account Google yang dipakai
+type serviceAccount struct {
+	ProjectID   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+func newFCMSenderFromEnv() (*FCMSender, error) {
+	path := os.Getenv("FCM_CREDENTIALS_FILE")
+	if path == "" {
+		return nil, errors.New("FCM_CREDENTIALS_FILE wajib diisi untuk PUSH_DRIVER=fcm")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca FCM_CREDENTIALS_FILE: %w", err)
+	}
+	return NewFCMSender(data)
+}
+
+// NewFCMSender membuat sender dari isi file JSON service account Firebase
+func NewFCMSender(credentials []byte) (*FCMSender, error) {
+	var account serviceAccount
+	if err := json.Unmarshal(credentials, &account); err != nil {
+		return nil, fmt.Errorf("kredensial FCM tidak valid: %w", err)
+	}
+	if account.ProjectID == "" || account.ClientEmail == "" || account.PrivateKey == "" {
+		return nil, errors.New("kredensial FCM harus berisi project_id, client_email, dan private_key")
+	}
+
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(account.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("private_key kredensial FCM tidak valid: %w", err)
+	}
+
+	s := &FCMSender{
+		ProjectID:   account.ProjectID,
+		ClientEmail: account.ClientEmail,
+		PrivateKey:  key,
+		TokenURL:    account.TokenURI,
+		Endpoint:    "https://fcm.googleapis.com",
+	}
+	if s.TokenURL == "" {
+		s.TokenURL = "https://oauth2.googleapis.com/token"
+	}
+	return s, nil
+}
+
+// fcmError adalah body error API FCM
+type fcmError struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+		Details []struct {
+			ErrorCode string `json:"errorCode"`
+		} `json:"details"`
+	} `json:"error"`
+}
+
+func (s *FCMSender) Send(ctx context.Context, device Device, msg Message) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"message": map[string]interface{}{
+			"token":        device.Token,
+			"notification": map[string]string{"title": msg.Title, "body": msg.Body},
+			"data":         msg.Data,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	token, err := s.token(ctx)
+	if err != nil {
+		return err
+	}
+
+	endpoint := strings.TrimRight(s.Endpoint, "/") + "/v1/projects/" + url.PathEscape(s.ProjectID) + "/messages:send"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := fcmClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	var apiErr fcmError
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	json.Unmarshal(data, &apiErr)
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		// Access token ditolak; buat ulang pada pengiriman berikutnya
+		s.mu.Lock()
+		s.accessToken = ""
+		s.mu.Unlock()
+	}
+	if fcmInvalidToken(apiErr) {
+		return ErrInvalidToken
+	}
+	return fmt.Errorf("push: FCM membalas %d: %s", resp.StatusCode, apiErr.Error.Message)
+}
+
+// fcmInvalidToken mengecek apakah error FCM berarti token perangkat sudah tidak
+// berlaku: aplikasi sudah dihapus (UNREGISTERED), token milik project lain
+// (SENDER_ID_MISMATCH), atau formatnya bukan token FCM
+func fcmInvalidToken(apiErr fcmError) bool {
+	for _, detail := range apiErr.Error.Details {
+		switch detail.ErrorCode {
+		case "UNREGISTERED", "SENDER_ID_MISMATCH":
+			return true
+		case "INVALID_ARGUMENT":
+			// INVALID_ARGUMENT juga dipakai untuk payload yang salah, jadi hanya
+			// dianggap token mati jika pesannya menyebut token
+			return strings.Contains(strings.ToLower(apiErr.Error.Message), "registration token")
+		}
+	}
+	return false
+}
+
+// token mengembalikan access token OAuth2, membuat yang baru jika belum ada atau hampir kedaluwarsa
+func (s *FCMSender) token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.accessToken != "" && time.Now().Before(s.expiresAt) {
+		return s.accessToken, nil
+	}
+
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   s.ClientEmail,
+		"scope": fcmScope,
+		"aud":   s.TokenURL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(s.PrivateKey)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := fcmClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+		Error       string `json:"error"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&result); err != nil {
+		return "", fmt.Errorf("push: respons token OAuth2 tidak valid: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || result.AccessToken == "" {
+		return "", fmt.Errorf("push: gagal mengambil access token FCM (%d): %s", resp.StatusCode, result.Error)
+	}
+
+	// Diperbarui satu menit sebelum kedaluwarsa agar tidak habis di tengah request
+	s.accessToken = result.AccessToken
+	s.expiresAt = now.Add(time.Duration(result.ExpiresIn)*time.Second - time.Minute)
+	return s.accessToken, nil
+}
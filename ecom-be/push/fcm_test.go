@@ -0,0 +1,130 @@
+package push
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newTestFCM membuat FCMSender yang mengarah ke server palsu. Token perangkat
+// "token-mati" dibalas UNREGISTERED seperti aplikasi yang sudah dihapus.
+func newTestFCM(t *testing.T) (*FCMSender, *[]map[string]interface{}, *int) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var messages []map[string]interface{}
+	tokenRequests := 0
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		tokenRequests++
+		if r.FormValue("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer" || r.FormValue("assertion") == "" {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"access_token":"akses","expires_in":3600}`))
+	})
+	mux.HandleFunc("/v1/projects/toko/messages:send", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer akses" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var body struct {
+			Message map[string]interface{} `json:"message"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.Message["token"] == "token-mati" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"code":404,"message":"Requested entity was not found.","status":"NOT_FOUND",` +
+				`"details":[{"@type":"type.googleapis.com/google.firebase.fcm.v1.FcmError","errorCode":"UNREGISTERED"}]}}`))
+			return
+		}
+		messages = append(messages, body.Message)
+		w.Write([]byte(`{"name":"projects/toko/messages/1"}`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	credentials, _ := json.Marshal(map[string]string{
+		"project_id":   "toko",
+		"client_email": "push@toko.iam.gserviceaccount.com",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"token_uri":    server.URL + "/token",
+	})
+	sender, err := NewFCMSender(credentials)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender.Endpoint = server.URL
+	return sender, &messages, &tokenRequests
+}
+
+func TestFCMSenderSendsMessage(t *testing.T) {
+	sender, messages, tokenRequests := newTestFCM(t)
+	msg := Message{Title: "Pesanan dikirim", Body: "Pesanan #7 sedang dikirim", Data: map[string]string{"order_id": "7"}}
+
+	for _, token := range []string{"token-a", "token-b"} {
+		if err := sender.Send(context.Background(), Device{Platform: PlatformAndroid, Token: token}, msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if *tokenRequests != 1 {
+		t.Errorf("access token diminta %d kali, seharusnya 1", *tokenRequests)
+	}
+	if len(*messages) != 2 {
+		t.Fatalf("pesan terkirim = %d, seharusnya 2", len(*messages))
+	}
+	sent := (*messages)[0]
+	notification, _ := sent["notification"].(map[string]interface{})
+	data, _ := sent["data"].(map[string]interface{})
+	if sent["token"] != "token-a" || notification["title"] != msg.Title || data["order_id"] != "7" {
+		t.Errorf("pesan = %v", sent)
+	}
+}
+
+func TestFCMSenderReportsInvalidToken(t *testing.T) {
+	sender, _, _ := newTestFCM(t)
+
+	err := sender.Send(context.Background(), Device{Platform: PlatformIOS, Token: "token-mati"}, Message{Title: "Halo"})
+	if !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("error = %v, seharusnya ErrInvalidToken", err)
+	}
+}
+
+func TestFCMInvalidToken(t *testing.T) {
+	tests := []struct {
+		body    string
+		invalid bool
+	}{
+		{`{"error":{"details":[{"errorCode":"UNREGISTERED"}]}}`, true},
+		{`{"error":{"details":[{"errorCode":"SENDER_ID_MISMATCH"}]}}`, true},
+		{`{"error":{"message":"The registration token is not a valid FCM registration token","details":[{"errorCode":"INVALID_ARGUMENT"}]}}`, true},
+		{`{"error":{"message":"Invalid value at 'message.data'","details":[{"errorCode":"INVALID_ARGUMENT"}]}}`, false},
+		{`{"error":{"details":[{"errorCode":"QUOTA_EXCEEDED"}]}}`, false},
+		{`{}`, false},
+	}
+
+	for _, tt := range tests {
+		var apiErr fcmError
+		json.Unmarshal([]byte(tt.body), &apiErr)
+		if got := fcmInvalidToken(apiErr); got != tt.invalid {
+			t.Errorf("%s: invalid = %v, seharusnya %v", tt.body, got, tt.invalid)
+		}
+	}
+}
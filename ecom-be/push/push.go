@@ -0,0 +1,89 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// Platform perangkat yang didukung. Dengan PUSH_DRIVER=fcm, kedua platform
+// mendaftarkan token FCM; FCM meneruskan notifikasi iOS ke APNs.
+const (
+	PlatformAndroid = "android"
+	PlatformIOS     = "ios"
+)
+
+// ErrInvalidToken dikembalikan sender jika token perangkat sudah tidak berlaku
+// (aplikasi dihapus, token kedaluwarsa). Token seperti ini harus dihapus.
+var ErrInvalidToken = errors.New("push: token perangkat tidak berlaku")
+
+// Device adalah tujuan pengiriman push notification
+type Device struct {
+	Platform string
+	Token    string
+}
+
+// Message adalah isi push notification
+type Message struct {
+	Title string
+	Body  string
+	Data  map[string]string // payload tambahan untuk aplikasi, mis. order_id
+}
+
+// Sender mengirim push notification ke satu perangkat melalui FCM/APNs
+type Sender interface {
+	Send(ctx context.Context, device Device, msg Message) error
+}
+
+// Default adalah sender yang dipakai aplikasi, diatur oleh Setup
+var Default Sender = LogSender{}
+
+// Setup memilih implementasi sender berdasarkan PUSH_DRIVER (log, fcm, disabled)
+func Setup() error {
+	switch strings.ToLower(os.Getenv("PUSH_DRIVER")) {
+	case "fcm":
+		s, err := newFCMSenderFromEnv()
+		if err != nil {
+			return err
+		}
+		Default = s
+	case "disabled", "none":
+		Default = NoopSender{}
+	case "", "log":
+		Default = LogSender{}
+	default:
+		return fmt.Errorf("PUSH_DRIVER %q tidak dikenal", os.Getenv("PUSH_DRIVER"))
+	}
+	return nil
+}
+
+// Send mengirim push notification melalui sender default
+func Send(ctx context.Context, device Device, msg Message) error {
+	return Default.Send(ctx, device, msg)
+}
+
+// LogSender hanya menulis push notification ke log, cocok untuk development
+type LogSender struct{}
+
+func (LogSender) Send(ctx context.Context, device Device, msg Message) error {
+	log.Printf("[push] platform=%s token=%s title=%q body=%q data=%v", device.Platform, shortToken(device.Token), msg.Title, msg.Body, msg.Data)
+	return nil
+}
+
+// NoopSender mengabaikan semua push notification
+type NoopSender struct{}
+
+func (NoopSender) Send(ctx context.Context, device Device, msg Message) error {
+	return nil
+}
+
+// shortToken memotong token agar tidak tertulis utuh di log
+func shortToken(token string) string {
+	if len(token) <= 12 {
+		return token
+	}
+	return token[:12] + "..."
+}
@@ -3,6 +3,8 @@ package routes
 import (
 	"ecom-be/controllers"
 	"ecom-be/middleware"
+	"ecom-be/models"
+	"ecom-be/storage"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -16,16 +18,57 @@ func SetupRouter() *gin.Engine {
 	config.AllowAllOrigins = true
 	config.AllowCredentials = true
 	config.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization"}
+	config.AllowHeaders = []string{
+		"Origin", "Content-Type", "Accept", "Authorization",
+		middleware.APIKeyHeader,
+		middleware.AppVersionHeader,
+		middleware.AppPlatformHeader,
+		"X-Guest-Token",
+		"X-Order-Token",
+	}
 	r.Use(cors.New(config))
 
+	// Public key untuk verifikasi JWT oleh layanan lain
+	r.GET("/.well-known/jwks.json", controllers.JWKS)
+
+	// File upload (gambar produk) disajikan langsung jika memakai storage lokal
+	if local, ok := storage.Default.(storage.LocalStorage); ok {
+		r.Static(storage.LocalURLPath, local.Dir)
+	}
+
+	// Konfigurasi aplikasi mobile didaftarkan sebelum pemeriksaan versi agar
+	// build lama tetap bisa membaca pesan update
+	r.GET("/app/config", controllers.GetAppConfig)
+
+	// Build aplikasi di bawah versi minimum mendapat 426 Upgrade Required
+	r.Use(middleware.RequireMinimumAppVersion())
+
 	// Rute tanpa autentikasi
 	r.POST("/register", controllers.Register)
 	r.POST("/login", controllers.Login)
+	r.POST("/login/2fa", controllers.LoginTwoFactor)
+	r.POST("/auth/oidc/:provider", controllers.OIDCLogin)
+	r.POST("/refresh", controllers.RefreshToken)
+	r.POST("/forgot-password", controllers.ForgotPassword)
+	r.POST("/reset-password", controllers.ResetPassword)
+	r.GET("/verify-email", controllers.VerifyEmail)
 
 	// Rute untuk produk (publik)
 	r.GET("/products", controllers.GetProducts)
 	r.GET("/products/:id", controllers.GetProduct)
+	r.GET("/categories", controllers.GetCategories)
+
+	// Keranjang dan checkout tamu (tanpa akun)
+	guest := r.Group("/guest")
+	{
+		guest.GET("/cart", controllers.GetGuestCart)
+		guest.POST("/cart", controllers.AddToGuestCart)
+		guest.PUT("/cart/:id", controllers.UpdateGuestCartItem)
+		guest.DELETE("/cart/:id", controllers.RemoveFromGuestCart)
+		guest.POST("/orders", controllers.GuestCheckout)
+		guest.GET("/orders/lookup", controllers.GetGuestOrder)
+		guest.POST("/orders/cancel", controllers.CancelGuestOrder)
+	}
 
 	// Rute dengan autentikasi
 	authenticated := r.Group("/api")
@@ -33,6 +76,39 @@ func SetupRouter() *gin.Engine {
 	{
 		// Profil user
 		authenticated.GET("/profile", controllers.GetProfile)
+		authenticated.PUT("/profile", controllers.UpdateProfile)
+		authenticated.PUT("/profile/email", controllers.UpdateEmail)
+		authenticated.PUT("/profile/password", controllers.ChangePassword)
+		authenticated.POST("/verify-email/resend", controllers.ResendVerification)
+
+		// Data pribadi dan penghapusan akun
+		authenticated.GET("/account/export", controllers.ExportAccount)
+		authenticated.DELETE("/account", controllers.DeleteAccount)
+
+		// Two-factor authentication
+		authenticated.POST("/2fa/setup", controllers.SetupTwoFactor)
+		authenticated.POST("/2fa/enable", controllers.EnableTwoFactor)
+		authenticated.POST("/2fa/disable", controllers.DisableTwoFactor)
+		authenticated.POST("/2fa/recovery-codes", controllers.RegenerateRecoveryCodes)
+
+		// Perangkat untuk push notification
+		authenticated.GET("/devices", controllers.GetDevices)
+		authenticated.POST("/devices", controllers.RegisterDevice)
+		authenticated.DELETE("/devices", controllers.UnregisterDevice)
+
+		// Sesi login aktif
+		authenticated.GET("/sessions", controllers.GetSessions)
+		authenticated.DELETE("/sessions/:id", controllers.DeleteSession)
+
+		// Logout
+		authenticated.POST("/logout", controllers.Logout)
+		authenticated.POST("/logout-all", controllers.LogoutAll)
+
+		// Buku alamat
+		authenticated.GET("/addresses", controllers.GetAddresses)
+		authenticated.POST("/addresses", controllers.CreateAddress)
+		authenticated.PUT("/addresses/:id", controllers.UpdateAddress)
+		authenticated.DELETE("/addresses/:id", controllers.DeleteAddress)
 
 		// Cart
 		authenticated.GET("/cart", controllers.GetCart)
@@ -41,6 +117,12 @@ func SetupRouter() *gin.Engine {
 		authenticated.DELETE("/cart/:id", controllers.RemoveFromCart)
 		authenticated.DELETE("/cart", controllers.ClearCart)
 
+		// Wishlist
+		authenticated.GET("/wishlist", controllers.GetWishlist)
+		authenticated.POST("/wishlist", controllers.AddToWishlist)
+		authenticated.DELETE("/wishlist/:productId", controllers.RemoveFromWishlist)
+		authenticated.POST("/wishlist/:productId/move-to-cart", controllers.MoveWishlistToCart)
+
 		// Pesanan
 		authenticated.POST("/orders", controllers.CreateOrder)
 		authenticated.GET("/orders", controllers.GetOrders)
@@ -48,19 +130,62 @@ func SetupRouter() *gin.Engine {
 		authenticated.PUT("/orders/:id/cancel", controllers.CancelOrder)
 	}
 
-	// Rute untuk admin
+	// Rute untuk admin, tiap aksi dibatasi dengan permission
+	// Rute admin bisa diakses dengan Bearer JWT atau X-API-Key sesuai scope-nya
 	admin := r.Group("/admin")
-	admin.Use(middleware.AuthRequired())
-	admin.Use(middleware.AdminRequired())
+	admin.Use(middleware.AuthRequiredOrAPIKey())
 	{
 		// Manajemen produk
-		admin.POST("/products", controllers.CreateProduct)
-		admin.PUT("/products/:id", controllers.UpdateProduct)
-		admin.DELETE("/products/:id", controllers.DeleteProduct)
+		admin.POST("/products", middleware.RequirePermission(models.PermProductsWrite), controllers.CreateProduct)
+		admin.PUT("/products/:id", middleware.RequirePermission(models.PermProductsWrite), controllers.UpdateProduct)
+		admin.DELETE("/products/:id", middleware.RequirePermission(models.PermProductsDelete), controllers.DeleteProduct)
+		admin.PUT("/products/:id/categories", middleware.RequirePermission(models.PermProductsWrite), controllers.SetProductCategories)
+		admin.PUT("/products/:id/options", middleware.RequirePermission(models.PermProductsWrite), controllers.SetProductOptions)
+		admin.POST("/products/:id/variants", middleware.RequirePermission(models.PermProductsWrite), controllers.CreateProductVariant)
+		admin.PUT("/products/:id/variants/:variantId", middleware.RequirePermission(models.PermProductsWrite), controllers.UpdateProductVariant)
+		admin.DELETE("/products/:id/variants/:variantId", middleware.RequirePermission(models.PermProductsDelete), controllers.DeleteProductVariant)
+		admin.POST("/products/:id/images", middleware.RequirePermission(models.PermProductsWrite), controllers.UploadProductImage)
+		admin.PUT("/products/:id/images", middleware.RequirePermission(models.PermProductsWrite), controllers.ReorderProductImages)
+		admin.PUT("/products/:id/images/:imageId/primary", middleware.RequirePermission(models.PermProductsWrite), controllers.SetPrimaryProductImage)
+		admin.DELETE("/products/:id/images/:imageId", middleware.RequirePermission(models.PermProductsWrite), controllers.DeleteProductImage)
+
+		// Manajemen kategori
+		admin.POST("/categories", middleware.RequirePermission(models.PermProductsWrite), controllers.CreateCategory)
+		admin.PUT("/categories/:id", middleware.RequirePermission(models.PermProductsWrite), controllers.UpdateCategory)
+		admin.DELETE("/categories/:id", middleware.RequirePermission(models.PermProductsDelete), controllers.DeleteCategory)
 
 		// Manajemen pesanan
-		admin.GET("/orders", controllers.GetAllOrders)
-		admin.PUT("/orders/:id/status", controllers.UpdateOrderStatus)
+		admin.GET("/orders", middleware.RequirePermission(models.PermOrdersRead), controllers.GetAllOrders)
+		admin.PUT("/orders/:id/status", middleware.RequirePermission(models.PermOrdersUpdate), controllers.UpdateOrderStatus)
+
+		// Manajemen user
+		admin.GET("/users", middleware.RequirePermission(models.PermUsersRead), controllers.AdminListUsers)
+		admin.GET("/users/:id", middleware.RequirePermission(models.PermUsersRead), controllers.AdminGetUser)
+		admin.PUT("/users/:id/role", middleware.RequirePermission(models.PermUsersWrite), controllers.AdminUpdateUserRole)
+		admin.PUT("/users/:id/suspend", middleware.RequirePermission(models.PermUsersWrite), controllers.AdminSuspendUser)
+		admin.PUT("/users/:id/reactivate", middleware.RequirePermission(models.PermUsersWrite), controllers.AdminReactivateUser)
+		admin.GET("/login-attempts", middleware.RequirePermission(models.PermUsersRead), controllers.GetLoginAttempts)
+
+		// Manajemen role dan permission
+		admin.GET("/permissions", middleware.RequirePermission(models.PermRolesManage), controllers.GetPermissions)
+		admin.GET("/roles", middleware.RequirePermission(models.PermRolesManage), controllers.GetRoles)
+		admin.POST("/roles", middleware.RequirePermission(models.PermRolesManage), controllers.CreateRole)
+		admin.PUT("/roles/:id", middleware.RequirePermission(models.PermRolesManage), controllers.UpdateRole)
+		admin.DELETE("/roles/:id", middleware.RequirePermission(models.PermRolesManage), controllers.DeleteRole)
+
+		// API key integrasi (scope api_keys:manage tidak bisa dimiliki API key)
+		admin.GET("/api-keys", middleware.RequirePermission(models.PermAPIKeysManage), controllers.AdminListAPIKeys)
+		admin.POST("/api-keys", middleware.RequirePermission(models.PermAPIKeysManage), controllers.AdminCreateAPIKey)
+		admin.DELETE("/api-keys/:id", middleware.RequirePermission(models.PermAPIKeysManage), controllers.AdminRevokeAPIKey)
+
+		// Konfigurasi aplikasi mobile
+		admin.GET("/app-config", middleware.RequirePermission(models.PermAppConfigManage), controllers.AdminGetAppConfig)
+		admin.PUT("/app-config/platforms/:platform", middleware.RequirePermission(models.PermAppConfigManage), controllers.AdminUpdateAppPlatform)
+		admin.POST("/app-config/messages", middleware.RequirePermission(models.PermAppConfigManage), controllers.AdminCreateAppMessage)
+		admin.PUT("/app-config/messages/:id", middleware.RequirePermission(models.PermAppConfigManage), controllers.AdminUpdateAppMessage)
+		admin.DELETE("/app-config/messages/:id", middleware.RequirePermission(models.PermAppConfigManage), controllers.AdminDeleteAppMessage)
+		admin.PUT("/app-config/features/:key", middleware.RequirePermission(models.PermAppConfigManage), controllers.AdminUpdateFeatureFlag)
+		admin.DELETE("/app-config/features/:key", middleware.RequirePermission(models.PermAppConfigManage), controllers.AdminDeleteFeatureFlag)
 	}
 
 	return r
@@ -0,0 +1,264 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/blevesearch/bleve/v2/analysis"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/custom"
+	"github.com/blevesearch/bleve/v2/analysis/lang/en"
+	"github.com/blevesearch/bleve/v2/analysis/lang/id"
+	"github.com/blevesearch/bleve/v2/analysis/token/lowercase"
+	"github.com/blevesearch/bleve/v2/analysis/tokenizer/unicode"
+	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/registry"
+)
+
+// analyzerName adalah nama analyzer teks produk di mapping index
+const analyzerName = "produk"
+
+// indonesianStemmerName adalah nama token filter stemmer bahasa Indonesia;
+// Bleve hanya menyediakan stopword bahasa Indonesia tanpa stemmer
+const indonesianStemmerName = "stemmer_id_tala"
+
+// language berisi token filter stopword dan stemmer Bleve untuk satu bahasa
+type language struct {
+	stop string
+	stem string
+}
+
+var languages = map[string]language{
+	"id": {stop: id.StopName, stem: indonesianStemmerName},
+	"en": {stop: en.StopName, stem: en.PluralStemmerName},
+}
+
+// registerFilters mendaftarkan token filter buatan sendiri ke registry Bleve
+// sekali saja, sebelum mapping pertama dibuat
+var registerFilters = sync.OnceValue(func() error {
+	return registry.RegisterTokenFilter(indonesianStemmerName,
+		func(config map[string]interface{}, cache *registry.Cache) (analysis.TokenFilter, error) {
+			return indonesianStemmer{}, nil
+		})
+})
+
+// newMapping membuat mapping index produk dengan analyzer untuk bahasa-bahasa
+// tertentu (id, en). Semua stopword dibuang sebelum stemmer dijalankan agar
+// stopword satu bahasa tidak lolos karena diubah stemmer bahasa lain.
+func newMapping(langs []string) (mapping.IndexMapping, error) {
+	if err := registerFilters(); err != nil {
+		return nil, err
+	}
+
+	filters := []string{lowercase.Name}
+	var stemmers []string
+	for _, name := range langs {
+		lang, ok := languages[name]
+		if !ok {
+			return nil, fmt.Errorf("bahasa pencarian %q tidak dikenal", name)
+		}
+		filters = append(filters, lang.stop)
+		stemmers = append(stemmers, lang.stem)
+	}
+
+	m := mapping.NewIndexMapping()
+	err := m.AddCustomAnalyzer(analyzerName, map[string]interface{}{
+		"type":          custom.Name,
+		"tokenizer":     unicode.Name,
+		"token_filters": append(filters, stemmers...),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Hanya field teks yang diindeks; ID dokumen disimpan sebagai ID Bleve
+	doc := mapping.NewDocumentStaticMapping()
+	for name := range fieldBoosts {
+		text := mapping.NewTextFieldMapping()
+		text.Analyzer = analyzerName
+		text.Store = false
+		text.IncludeTermVectors = false
+		text.DocValues = false
+		doc.AddFieldMappingsAt(name, text)
+	}
+	m.DefaultMapping = doc
+	m.DefaultAnalyzer = analyzerName
+	return m, nil
+}
+
+// indonesianStemmer adalah token filter Bleve untuk stemIndonesian
+type indonesianStemmer struct{}
+
+func (indonesianStemmer) Filter(input analysis.TokenStream) analysis.TokenStream {
+	for _, token := range input {
+		if !token.KeyWord {
+			token.Term = []byte(stemIndonesian(string(token.Term)))
+		}
+	}
+	return input
+}
+
+// Flag imbuhan yang sudah dibuang, dipakai untuk aturan pembuangan akhiran
+const (
+	removedDi = 1 << iota
+	removedMeng
+	removedPeng
+	removedTer
+	removedKe
+	removedBer
+	removedPe
+)
+
+// stemIndonesian adalah stemmer ringan bahasa Indonesia tanpa kamus (algoritma
+// Tala, seperti IndonesianStemmer di Lucene). Kata dengan dua suku kata atau
+// kurang tidak diubah agar kata dasar pendek tidak rusak.
+func stemIndonesian(word string) string {
+	s := &idStemmer{word: []rune(word)}
+	for _, r := range s.word {
+		if isVowel(r) {
+			s.syllables++
+		}
+	}
+
+	if s.syllables > 2 {
+		s.removeParticle()
+	}
+	if s.syllables > 2 {
+		s.removePossessive()
+	}
+
+	before := len(s.word)
+	if s.syllables > 2 {
+		s.removeFirstOrderPrefix()
+	}
+	if len(s.word) != before {
+		before = len(s.word)
+		if s.syllables > 2 {
+			s.removeSuffix()
+		}
+		if len(s.word) != before && s.syllables > 2 {
+			s.removeSecondOrderPrefix()
+		}
+	} else {
+		if s.syllables > 2 {
+			s.removeSecondOrderPrefix()
+		}
+		if s.syllables > 2 {
+			s.removeSuffix()
+		}
+	}
+	return string(s.word)
+}
+
+type idStemmer struct {
+	word      []rune
+	syllables int
+	flags     int
+}
+
+func isVowel(r rune) bool {
+	switch r {
+	case 'a', 'e', 'i', 'o', 'u':
+		return true
+	}
+	return false
+}
+
+func (s *idStemmer) hasPrefix(prefix string) bool {
+	return strings.HasPrefix(string(s.word), prefix)
+}
+
+func (s *idStemmer) hasSuffix(suffix string) bool {
+	return strings.HasSuffix(string(s.word), suffix)
+}
+
+// trimPrefix membuang n huruf di depan dan satu suku kata
+func (s *idStemmer) trimPrefix(n, flag int) {
+	s.word = s.word[n:]
+	s.flags |= flag
+	s.syllables--
+}
+
+// trimSuffix membuang n huruf di belakang dan satu suku kata
+func (s *idStemmer) trimSuffix(n int) {
+	s.word = s.word[:len(s.word)-n]
+	s.syllables--
+}
+
+func (s *idStemmer) removeParticle() {
+	for _, particle := range []string{"kah", "lah", "pun"} {
+		if s.hasSuffix(particle) {
+			s.trimSuffix(3)
+			return
+		}
+	}
+}
+
+func (s *idStemmer) removePossessive() {
+	switch {
+	case s.hasSuffix("ku"), s.hasSuffix("mu"):
+		s.trimSuffix(2)
+	case s.hasSuffix("nya"):
+		s.trimSuffix(3)
+	}
+}
+
+func (s *idStemmer) removeFirstOrderPrefix() {
+	switch {
+	case s.hasPrefix("meng"):
+		s.trimPrefix(4, removedMeng)
+	case s.hasPrefix("meny") && len(s.word) > 4 && isVowel(s.word[4]):
+		s.word[3] = 's'
+		s.trimPrefix(3, removedMeng)
+	case s.hasPrefix("men"), s.hasPrefix("mem"):
+		s.trimPrefix(3, removedMeng)
+	case s.hasPrefix("me"):
+		s.trimPrefix(2, removedMeng)
+	case s.hasPrefix("peng"):
+		s.trimPrefix(4, removedPeng)
+	case s.hasPrefix("peny") && len(s.word) > 4 && isVowel(s.word[4]):
+		s.word[3] = 's'
+		s.trimPrefix(3, removedPeng)
+	case s.hasPrefix("peny"):
+		s.trimPrefix(4, removedPeng)
+	case s.hasPrefix("pen") && len(s.word) > 3 && isVowel(s.word[3]):
+		s.word[2] = 't'
+		s.trimPrefix(2, removedPeng)
+	case s.hasPrefix("pen"), s.hasPrefix("pem"):
+		s.trimPrefix(3, removedPeng)
+	case s.hasPrefix("di"):
+		s.trimPrefix(2, removedDi)
+	case s.hasPrefix("ter"):
+		s.trimPrefix(3, removedTer)
+	case s.hasPrefix("ke"):
+		s.trimPrefix(2, removedKe)
+	}
+}
+
+func (s *idStemmer) removeSecondOrderPrefix() {
+	switch {
+	case s.hasPrefix("ber"):
+		s.trimPrefix(3, removedBer)
+	case len(s.word) == 7 && s.hasPrefix("belajar"):
+		s.trimPrefix(3, removedBer)
+	case s.hasPrefix("be") && len(s.word) > 4 && !isVowel(s.word[2]) && s.word[3] == 'e' && s.word[4] == 'r':
+		s.trimPrefix(2, removedBer)
+	case s.hasPrefix("per"):
+		s.trimPrefix(3, 0)
+	case len(s.word) == 7 && s.hasPrefix("pelajar"):
+		s.trimPrefix(3, 0)
+	case s.hasPrefix("pe"):
+		s.trimPrefix(2, removedPe)
+	}
+}
+
+func (s *idStemmer) removeSuffix() {
+	switch {
+	case s.hasSuffix("kan") && s.flags&(removedKe|removedPeng|removedPe) == 0:
+		s.trimSuffix(3)
+	case s.hasSuffix("an") && s.flags&(removedDi|removedMeng|removedTer) == 0:
+		s.trimSuffix(2)
+	case s.hasSuffix("i") && !s.hasSuffix("si") && s.flags&(removedBer|removedKe|removedPeng) == 0:
+		s.trimSuffix(1)
+	}
+}
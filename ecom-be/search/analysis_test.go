@@ -0,0 +1,35 @@
+package search
+
+import "testing"
+
+func TestStemIndonesian(t *testing.T) {
+	tests := map[string]string{
+		"membaca":   "baca",
+		"dibaca":    "baca",
+		"menyapu":   "sapu",
+		"pengukur":  "ukur",
+		"berlari":   "lari",
+		"pelajar":   "ajar",
+		"terbaru":   "baru",
+		"makanan":   "makan",
+		"bukunya":   "buku",
+		"bukukah":   "buku",
+		"sepatunya": "sepatu",
+		// Kata dengan dua suku kata atau kurang tidak diubah
+		"mandi":  "mandi",
+		"laptop": "laptop",
+		"tas":    "tas",
+	}
+
+	for word, want := range tests {
+		if got := stemIndonesian(word); got != want {
+			t.Errorf("stemIndonesian(%q) = %q, seharusnya %q", word, got, want)
+		}
+	}
+}
+
+func TestNewMappingRejectsUnknownLanguage(t *testing.T) {
+	if _, err := newMapping([]string{"id", "xx"}); err == nil {
+		t.Error("bahasa yang tidak dikenal seharusnya ditolak")
+	}
+}
@@ -0,0 +1,569 @@
+package search
+
+import (
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/search/query"
+)
+
+// fieldBoosts memberi bobot kecocokan per field: nama paling menentukan relevansi
+var fieldBoosts = map[string]float64{
+	"Name":        3,
+	"Categories":  2,
+	"Description": 1,
+}
+
+// currentFile berisi nama generasi index yang aktif di dalam direktori index
+const currentFile = "current"
+
+// languagesKey menyimpan bahasa analyzer di dalam index untuk mendeteksi
+// index yang dibuat dengan SEARCH_LANGUAGES berbeda
+var languagesKey = []byte("languages")
+
+// changeRetention adalah lama perubahan dokumen diingat untuk disusulkan ke
+// generasi baru yang dibangun proses lain
+const changeRetention = 24 * time.Hour
+
+// Document adalah data produk yang diindeks
+type Document struct {
+	ID          uint
+	Name        string
+	Description string
+	// Categories berisi nama kategori produk beserta induk-induknya
+	Categories []string
+}
+
+// Result adalah dokumen yang cocok beserta skor relevansinya
+type Result struct {
+	ID    uint
+	Score float64
+}
+
+// Index adalah index full-text Bleve. Di disk, setiap pembangunan ulang
+// menghasilkan generasi baru di direktori index dan file current menunjuk
+// generasi yang aktif, sehingga perintah reindex bisa membangun index baru
+// tanpa membuka index yang sedang dipakai server. Generasi aktif baru dibuka
+// saat index pertama kali dipakai.
+type Index struct {
+	dir     string // kosong berarti hanya di memori
+	langs   []string
+	mapping mapping.IndexMapping
+
+	// openMu melindungi pembukaan dan penggantian generasi; mu ditahan selama
+	// index dipakai agar generasi tidak ditutup di tengah pencarian atau penulisan
+	openMu     sync.Mutex
+	mu         sync.RWMutex
+	index      bleve.Index
+	generation string
+
+	changesMu sync.Mutex
+	changes   map[uint]time.Time // waktu perubahan terakhir per dokumen
+	refresh   func(ids []uint)
+}
+
+// NewIndex membuat index kosong yang hanya disimpan di memori
+func NewIndex(langs []string) (*Index, error) {
+	return Open("", langs)
+}
+
+// Open menyiapkan index di direktori dir. Generasi yang belum ada, rusak, atau
+// dibuat dengan bahasa berbeda diganti index kosong yang perlu dibangun ulang.
+func Open(dir string, langs []string) (*Index, error) {
+	m, err := newMapping(langs)
+	if err != nil {
+		return nil, err
+	}
+	return &Index{dir: dir, langs: langs, mapping: m, changes: map[uint]time.Time{}}, nil
+}
+
+// Empty mengembalikan true jika index belum berisi dokumen
+func (idx *Index) Empty() (bool, error) {
+	if err := idx.open(); err != nil {
+		return false, err
+	}
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	count, err := idx.index.DocCount()
+	return count == 0, err
+}
+
+// Put menambahkan atau mengganti dokumen
+func (idx *Index) Put(docs ...Document) error {
+	ids := make([]uint, 0, len(docs))
+	for _, doc := range docs {
+		ids = append(ids, doc.ID)
+	}
+	return idx.write(ids, func(batch *bleve.Batch) error {
+		return indexDocuments(batch, docs)
+	})
+}
+
+// Delete menghapus dokumen; ID yang tidak ada diabaikan
+func (idx *Index) Delete(ids ...uint) error {
+	return idx.write(ids, func(batch *bleve.Batch) error {
+		for _, id := range ids {
+			batch.Delete(docID(id))
+		}
+		return nil
+	})
+}
+
+// write menulis satu batch ke generasi aktif. Penggantian generasi menunggu
+// batch selesai, jadi perubahan tidak jatuh ke generasi yang sudah ditutup.
+func (idx *Index) write(ids []uint, fill func(batch *bleve.Batch) error) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := idx.open(); err != nil {
+		return err
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	batch := idx.index.NewBatch()
+	if err := fill(batch); err != nil {
+		return err
+	}
+	if err := idx.index.Batch(batch); err != nil {
+		return err
+	}
+	idx.recordChanges(ids)
+	return nil
+}
+
+// Rebuild membangun generasi baru dari dokumen yang dikirim load lewat put,
+// lalu menjadikannya generasi aktif. Perubahan yang ditulis proses ini selama
+// pembangunan disusulkan lewat fungsi refresh dari Watch.
+func (idx *Index) Rebuild(load func(put func(docs ...Document) error) error) error {
+	name := newGeneration()
+	path := ""
+	if idx.dir != "" {
+		if err := os.MkdirAll(idx.dir, 0o755); err != nil {
+			return err
+		}
+		path = filepath.Join(idx.dir, name)
+	}
+
+	index, err := idx.create(path)
+	if err != nil {
+		return err
+	}
+	err = load(func(docs ...Document) error {
+		batch := index.NewBatch()
+		if err := indexDocuments(batch, docs); err != nil {
+			return err
+		}
+		return index.Batch(batch)
+	})
+	if err != nil {
+		index.Close()
+		removeGeneration(path)
+		return err
+	}
+
+	if idx.dir == "" {
+		idx.openMu.Lock()
+		old, _ := idx.swap(index, name)
+		idx.openMu.Unlock()
+		if old != nil {
+			return old.Close()
+		}
+		return nil
+	}
+
+	// Generasi ditutup dulu agar proses lain, termasuk server, bisa membukanya
+	if err := index.Close(); err != nil {
+		removeGeneration(path)
+		return err
+	}
+	if current, err := readCurrent(idx.dir); err == nil && generationTime(current).After(generationTime(name)) {
+		removeGeneration(path)
+		return errors.New("index pencarian sudah dibangun ulang oleh proses lain")
+	}
+	if err := writeCurrent(idx.dir, name); err != nil {
+		removeGeneration(path)
+		return err
+	}
+
+	// Proses yang belum membuka index akan membuka generasi baru saat pertama dipakai
+	idx.mu.RLock()
+	opened := idx.index != nil
+	idx.mu.RUnlock()
+	if opened {
+		_, err = idx.reload()
+	}
+	return err
+}
+
+// Search mencari dokumen yang cocok dengan query, diurutkan dari yang paling
+// relevan, dan mengembalikan paling banyak limit hasil beserta jumlah semua
+// dokumen yang cocok. Setiap kata query dicocokkan ke bentuk dasarnya dan ke
+// term yang berbeda satu atau dua huruf.
+func (idx *Index) Search(text string, limit int) ([]Result, int, error) {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return nil, 0, nil
+	}
+
+	// Dokumen yang cocok dengan lebih banyak kata query diutamakan
+	disjuncts := make([]query.Query, 0, len(words))
+	for _, word := range words {
+		fields := make([]query.Query, 0, len(fieldBoosts))
+		for name, boost := range fieldBoosts {
+			match := bleve.NewMatchQuery(word)
+			match.SetField(name)
+			match.SetBoost(boost)
+			match.SetFuzziness(fuzziness(word))
+			fields = append(fields, match)
+		}
+		disjuncts = append(disjuncts, bleve.NewDisjunctionQuery(fields...))
+	}
+
+	req := bleve.NewSearchRequestOptions(bleve.NewDisjunctionQuery(disjuncts...), limit, 0, false)
+	req.SortBy([]string{"-_score", "_id"})
+
+	if err := idx.open(); err != nil {
+		return nil, 0, err
+	}
+	idx.mu.RLock()
+	res, err := idx.index.Search(req)
+	idx.mu.RUnlock()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	results := make([]Result, 0, len(res.Hits))
+	for _, hit := range res.Hits {
+		id, err := strconv.ParseUint(hit.ID, 10, 64)
+		if err != nil {
+			continue
+		}
+		results = append(results, Result{ID: uint(id), Score: hit.Score})
+	}
+	return results, int(res.Total), nil
+}
+
+// Watch memeriksa file current setiap interval dan memakai generasi baru yang
+// dibangun proses lain. Setelah generasi diganti, refresh dipanggil dengan ID
+// dokumen yang diubah proses ini sejak generasi tersebut mulai dibangun.
+func (idx *Index) Watch(interval time.Duration, refresh func(ids []uint)) {
+	idx.changesMu.Lock()
+	idx.refresh = refresh
+	idx.changesMu.Unlock()
+
+	if idx.dir == "" {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			idx.mu.RLock()
+			opened := idx.index != nil
+			idx.mu.RUnlock()
+			if !opened {
+				continue
+			}
+			if _, err := idx.reload(); err != nil {
+				log.Printf("Gagal memuat index pencarian baru: %v", err)
+			}
+		}
+	}()
+}
+
+// Close menutup generasi yang sedang dibuka
+func (idx *Index) Close() error {
+	idx.openMu.Lock()
+	defer idx.openMu.Unlock()
+
+	old, _ := idx.swap(nil, "")
+	if old == nil {
+		return nil
+	}
+	return old.Close()
+}
+
+// fuzziness menentukan jumlah huruf berbeda yang ditoleransi; kata pendek harus persis
+func fuzziness(word string) int {
+	switch n := len([]rune(word)); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// open membuka generasi aktif jika belum dibuka
+func (idx *Index) open() error {
+	idx.mu.RLock()
+	opened := idx.index != nil
+	idx.mu.RUnlock()
+	if opened {
+		return nil
+	}
+
+	idx.openMu.Lock()
+	defer idx.openMu.Unlock()
+	if idx.index != nil {
+		return nil
+	}
+
+	if idx.dir == "" {
+		index, err := idx.create("")
+		if err != nil {
+			return err
+		}
+		idx.swap(index, newGeneration())
+		return nil
+	}
+
+	if err := os.MkdirAll(idx.dir, 0o755); err != nil {
+		return err
+	}
+	name, err := readCurrent(idx.dir)
+	if err == nil {
+		index, err := idx.openGeneration(name)
+		if err == nil {
+			idx.swap(index, name)
+			removeOlderGenerations(idx.dir, name)
+			return nil
+		}
+		log.Printf("Index pencarian %s tidak bisa dipakai, membuat index kosong: %v", name, err)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	name = newGeneration()
+	index, err := idx.create(filepath.Join(idx.dir, name))
+	if err != nil {
+		return err
+	}
+	if err := writeCurrent(idx.dir, name); err != nil {
+		index.Close()
+		return err
+	}
+	idx.swap(index, name)
+	removeOlderGenerations(idx.dir, name)
+	return nil
+}
+
+// reload membuka generasi yang ditunjuk file current jika berbeda dari yang
+// sedang dipakai, lalu menutup dan menghapus generasi lama
+func (idx *Index) reload() (bool, error) {
+	idx.openMu.Lock()
+	name, err := readCurrent(idx.dir)
+	if err != nil || name == idx.generation {
+		idx.openMu.Unlock()
+		return false, err
+	}
+
+	index, err := idx.openGeneration(name)
+	if err != nil {
+		idx.openMu.Unlock()
+		return false, err
+	}
+	old, oldName := idx.swap(index, name)
+	idx.openMu.Unlock()
+
+	if old != nil {
+		if err := old.Close(); err != nil {
+			log.Printf("Gagal menutup index pencarian lama: %v", err)
+		}
+		removeGeneration(filepath.Join(idx.dir, oldName))
+	}
+
+	idx.changesMu.Lock()
+	refresh := idx.refresh
+	idx.changesMu.Unlock()
+	if refresh != nil {
+		if ids := idx.changedSince(generationTime(name)); len(ids) > 0 {
+			refresh(ids)
+		}
+	}
+	return true, nil
+}
+
+// swap memasang index sebagai generasi aktif dan mengembalikan generasi
+// sebelumnya; pemanggil harus menahan openMu
+func (idx *Index) swap(index bleve.Index, name string) (bleve.Index, string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	old, oldName := idx.index, idx.generation
+	idx.index, idx.generation = index, name
+	return old, oldName
+}
+
+// create membuat index kosong di path, atau di memori jika path kosong
+func (idx *Index) create(path string) (bleve.Index, error) {
+	var index bleve.Index
+	var err error
+	if path == "" {
+		index, err = bleve.NewMemOnly(idx.mapping)
+	} else {
+		index, err = bleve.New(path, idx.mapping)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := index.SetInternal(languagesKey, []byte(strings.Join(idx.langs, ","))); err != nil {
+		index.Close()
+		return nil, err
+	}
+	return index, nil
+}
+
+// openGeneration membuka generasi di direktori index dan memastikan bahasanya sama
+func (idx *Index) openGeneration(name string) (bleve.Index, error) {
+	index, err := bleve.Open(filepath.Join(idx.dir, name))
+	if err != nil {
+		return nil, err
+	}
+
+	langs, err := index.GetInternal(languagesKey)
+	if err == nil && string(langs) != strings.Join(idx.langs, ",") {
+		err = errors.New("index dibuat dengan bahasa " + string(langs))
+	}
+	if err != nil {
+		index.Close()
+		return nil, err
+	}
+	return index, nil
+}
+
+// recordChanges mencatat waktu perubahan dokumen dan melupakan perubahan yang
+// lebih lama dari changeRetention
+func (idx *Index) recordChanges(ids []uint) {
+	now := time.Now()
+	idx.changesMu.Lock()
+	defer idx.changesMu.Unlock()
+
+	for id, at := range idx.changes {
+		if now.Sub(at) > changeRetention {
+			delete(idx.changes, id)
+		}
+	}
+	for _, id := range ids {
+		idx.changes[id] = now
+	}
+}
+
+// changedSince mengembalikan ID dokumen yang diubah proses ini sejak waktu tertentu
+func (idx *Index) changedSince(since time.Time) []uint {
+	idx.changesMu.Lock()
+	defer idx.changesMu.Unlock()
+
+	var ids []uint
+	for id, at := range idx.changes {
+		if !at.Before(since) {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func indexDocuments(batch *bleve.Batch, docs []Document) error {
+	for _, doc := range docs {
+		if err := batch.Index(docID(doc.ID), doc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func docID(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+// newGeneration menamai generasi dengan waktu mulai pembangunannya
+func newGeneration() string {
+	return strconv.FormatInt(time.Now().UnixNano(), 10)
+}
+
+// generationTime mengembalikan waktu mulai pembangunan generasi, atau waktu nol
+// jika namanya bukan nama generasi
+func generationTime(name string) time.Time {
+	nanos, err := strconv.ParseInt(name, 10, 64)
+	if err != nil || nanos <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
+func readCurrent(dir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, currentFile))
+	if err != nil {
+		return "", err
+	}
+	name := strings.TrimSpace(string(data))
+	if generationTime(name).IsZero() {
+		return "", errors.New("isi file current index pencarian tidak valid")
+	}
+	return name, nil
+}
+
+// writeCurrent menulis file current lewat file sementara lalu me-rename-nya
+// agar pembaca tidak pernah melihat file yang setengah tertulis
+func writeCurrent(dir, name string) error {
+	tmp, err := os.CreateTemp(dir, currentFile+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(name); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, currentFile))
+}
+
+func removeGeneration(path string) {
+	if path == "" {
+		return
+	}
+	if err := os.RemoveAll(path); err != nil {
+		log.Printf("Gagal menghapus index pencarian lama %s: %v", path, err)
+	}
+}
+
+// removeOlderGenerations menghapus generasi yang lebih lama dari generasi
+// aktif, mis. sisa pembangunan yang terhenti. Generasi yang lebih baru bisa
+// jadi sedang dibangun proses lain, jadi dibiarkan.
+func removeOlderGenerations(dir, current string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	currentTime := generationTime(current)
+	for _, entry := range entries {
+		at := generationTime(entry.Name())
+		if entry.IsDir() && !at.IsZero() && at.Before(currentTime) {
+			removeGeneration(filepath.Join(dir, entry.Name()))
+		}
+	}
+}
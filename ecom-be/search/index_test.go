@@ -0,0 +1,177 @@
+package search
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+var testDocs = []Document{
+	{ID: 1, Name: "Sepatu Lari Pria", Description: "Sepatu ringan untuk olahraga", Categories: []string{"Sepatu Lari", "Olahraga"}},
+	{ID: 2, Name: "Kaos Olahraga", Description: "Nyaman dipakai bersama sepatu lari", Categories: []string{"Pakaian"}},
+	{ID: 3, Name: "Headphone Premium", Description: "Headphones dengan suara jernih", Categories: []string{"Elektronik"}},
+	{ID: 4, Name: "Smartphone XYZ", Description: "Smartphone canggih", Categories: []string{"Elektronik"}},
+}
+
+func newTestIndex(t *testing.T, docs ...Document) *Index {
+	t.Helper()
+	idx, err := NewIndex(DefaultLanguages)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := idx.Put(docs...); err != nil {
+		t.Fatal(err)
+	}
+	return idx
+}
+
+func searchIDs(t *testing.T, idx *Index, query string) []uint {
+	t.Helper()
+	results, _, err := idx.Search(query, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]uint, 0, len(results))
+	for _, result := range results {
+		ids = append(ids, result.ID)
+	}
+	return ids
+}
+
+func TestSearchRanksNameAboveDescription(t *testing.T) {
+	idx := newTestIndex(t, testDocs...)
+
+	ids := searchIDs(t, idx, "sepatu lari")
+	if !slices.Equal(ids, []uint{1, 2}) {
+		t.Errorf("hasil = %v, seharusnya [1 2]", ids)
+	}
+}
+
+func TestSearchMatchesStemsAndTypos(t *testing.T) {
+	idx := newTestIndex(t, testDocs...)
+
+	tests := map[string][]uint{
+		"sepatunya":   {1, 2}, // akhiran -nya
+		"headphones":  {3},    // bentuk jamak
+		"smartfone":   {4},    // dua huruf berbeda
+		"elektronk":   {3, 4}, // kategori, satu huruf hilang
+		"olahraga":    {1, 2},
+		"xyz":         {4},
+		"abc":         {}, // kata pendek harus persis
+		"yang dengan": {}, // hanya stopword
+	}
+	for query, want := range tests {
+		ids := searchIDs(t, idx, query)
+		slices.Sort(ids)
+		if !slices.Equal(ids, want) {
+			t.Errorf("Search(%q) = %v, seharusnya %v", query, ids, want)
+		}
+	}
+}
+
+func TestSearchReportsTotal(t *testing.T) {
+	idx := newTestIndex(t, testDocs...)
+
+	results, total, err := idx.Search("elektronik", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || total != 2 {
+		t.Errorf("hasil = %d, total = %d; seharusnya 1 dan 2", len(results), total)
+	}
+}
+
+func TestPutReplacesAndDeleteRemoves(t *testing.T) {
+	idx := newTestIndex(t, testDocs...)
+
+	if err := idx.Put(Document{ID: 3, Name: "Speaker Bluetooth"}); err != nil {
+		t.Fatal(err)
+	}
+	if ids := searchIDs(t, idx, "headphone"); len(ids) != 0 {
+		t.Errorf("dokumen lama masih ditemukan: %v", ids)
+	}
+	if ids := searchIDs(t, idx, "speaker"); !slices.Equal(ids, []uint{3}) {
+		t.Errorf("dokumen baru = %v, seharusnya [3]", ids)
+	}
+
+	if err := idx.Delete(3, 99); err != nil {
+		t.Fatal(err)
+	}
+	if ids := searchIDs(t, idx, "speaker"); len(ids) != 0 {
+		t.Errorf("dokumen yang dihapus masih ditemukan: %v", ids)
+	}
+}
+
+func TestRebuildFromAnotherProcess(t *testing.T) {
+	dir := t.TempDir()
+
+	// server membuka index; builder berperan sebagai perintah reindex
+	server, err := Open(dir, DefaultLanguages)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	if empty, err := server.Empty(); err != nil || !empty {
+		t.Fatalf("index baru: empty = %v, err = %v", empty, err)
+	}
+	oldGeneration := server.generation
+
+	var refreshed []uint
+	server.Watch(time.Hour, func(ids []uint) { refreshed = ids })
+
+	builder, err := Open(dir, DefaultLanguages)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = builder.Rebuild(func(put func(docs ...Document) error) error {
+		// Perubahan di server selama pembangunan harus disusulkan setelah swap
+		if err := server.Put(Document{ID: 9, Name: "Topi"}); err != nil {
+			return err
+		}
+		return put(testDocs...)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if builder.index != nil {
+		t.Error("perintah reindex tidak boleh membuka index")
+	}
+
+	swapped, err := server.reload()
+	if err != nil || !swapped {
+		t.Fatalf("reload: swapped = %v, err = %v", swapped, err)
+	}
+	if ids := searchIDs(t, server, "headphone"); !slices.Equal(ids, []uint{3}) {
+		t.Errorf("hasil setelah swap = %v, seharusnya [3]", ids)
+	}
+	if !slices.Equal(refreshed, []uint{9}) {
+		t.Errorf("refresh = %v, seharusnya [9]", refreshed)
+	}
+	if _, err := os.Stat(filepath.Join(dir, oldGeneration)); !os.IsNotExist(err) {
+		t.Errorf("generasi lama belum dihapus: %v", err)
+	}
+}
+
+func TestOpenDiscardsIndexWithOtherLanguages(t *testing.T) {
+	dir := t.TempDir()
+
+	english, err := Open(dir, []string{"en"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := english.Put(testDocs...); err != nil {
+		t.Fatal(err)
+	}
+	english.Close()
+
+	idx, err := Open(dir, DefaultLanguages)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+	if empty, err := idx.Empty(); err != nil || !empty {
+		t.Errorf("index dengan bahasa lain seharusnya diganti index kosong: empty = %v, err = %v", empty, err)
+	}
+}
@@ -0,0 +1,67 @@
+package search
+
+import (
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultLanguages adalah bahasa analyzer jika SEARCH_LANGUAGES kosong
+var DefaultLanguages = []string{"id", "en"}
+
+// Default adalah index produk yang dipakai aplikasi, diatur oleh Setup.
+// Sebelum Setup dipanggil index hanya disimpan di memori.
+var Default = mustIndex(NewIndex(DefaultLanguages))
+
+// Setup menyiapkan index di direktori SEARCH_INDEX_PATH (default data/search)
+// dengan analyzer untuk bahasa di SEARCH_LANGUAGES (default id,en)
+func Setup() error {
+	path := os.Getenv("SEARCH_INDEX_PATH")
+	if path == "" {
+		path = "data/search"
+	}
+
+	langs := DefaultLanguages
+	if value := os.Getenv("SEARCH_LANGUAGES"); value != "" {
+		langs = nil
+		for _, lang := range strings.Split(value, ",") {
+			if lang = strings.ToLower(strings.TrimSpace(lang)); lang != "" {
+				langs = append(langs, lang)
+			}
+		}
+	}
+
+	idx, err := Open(path, langs)
+	if err != nil {
+		return err
+	}
+	Default = idx
+	return nil
+}
+
+// Put menambahkan atau mengganti dokumen di index default
+func Put(docs ...Document) error {
+	return Default.Put(docs...)
+}
+
+// Delete menghapus dokumen dari index default
+func Delete(ids ...uint) error {
+	return Default.Delete(ids...)
+}
+
+// Search mencari dokumen di index default
+func Search(query string, limit int) ([]Result, int, error) {
+	return Default.Search(query, limit)
+}
+
+// Watch memuat generasi baru index default yang dibangun proses lain
+func Watch(interval time.Duration, refresh func(ids []uint)) {
+	Default.Watch(interval, refresh)
+}
+
+func mustIndex(idx *Index, err error) *Index {
+	if err != nil {
+		panic(err)
+	}
+	return idx
+}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStorage menyimpan file di disk server. File disajikan oleh server di
+// LocalURLPath, atau oleh CDN/web server jika PublicURL diarahkan ke sana.
+type LocalStorage struct {
+	Dir       string
+	PublicURL string
+}
+
+func (s LocalStorage) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	// Tulis ke file sementara dulu agar file tidak pernah terbaca setengah jadi
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (s LocalStorage) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s LocalStorage) URL(key string) string {
+	return joinURL(s.PublicURL, key)
+}
+
+// path mengubah key menjadi path file di dalam Dir dan menolak key yang keluar dari Dir
+func (s LocalStorage) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if clean == "." || filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", errors.New("storage: key tidak valid")
+	}
+	return filepath.Join(s.Dir, clean), nil
+}
@@ -0,0 +1,200 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+var s3Client = &http.Client{Timeout: 30 * time.Second}
+
+// S3Storage menyimpan file di bucket S3 atau layanan yang kompatibel (MinIO,
+// Cloudflare R2, dll). Request ditandatangani dengan AWS Signature Version 4.
+type S3Storage struct {
+	Endpoint  string // mis. https://s3.ap-southeast-1.amazonaws.com atau http://localhost:9000
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	// PathStyle memakai URL endpoint/bucket/key alih-alih bucket.endpoint/key (wajib untuk MinIO)
+	PathStyle bool
+	// PublicURL adalah base URL file untuk client, mis. domain CDN. Kosong berarti URL bucket.
+	PublicURL string
+}
+
+func newS3StorageFromEnv() (S3Storage, error) {
+	s := S3Storage{
+		Endpoint:  os.Getenv("S3_ENDPOINT"),
+		Region:    os.Getenv("S3_REGION"),
+		Bucket:    os.Getenv("S3_BUCKET"),
+		AccessKey: os.Getenv("S3_ACCESS_KEY"),
+		SecretKey: os.Getenv("S3_SECRET_KEY"),
+		PathStyle: os.Getenv("S3_PATH_STYLE") == "true",
+		PublicURL: os.Getenv("STORAGE_PUBLIC_URL"),
+	}
+	if s.Region == "" {
+		s.Region = "us-east-1"
+	}
+	if s.Endpoint == "" {
+		s.Endpoint = "https://s3." + s.Region + ".amazonaws.com"
+	}
+	if s.Bucket == "" || s.AccessKey == "" || s.SecretKey == "" {
+		return s, errors.New("S3_BUCKET, S3_ACCESS_KEY dan S3_SECRET_KEY wajib diisi untuk STORAGE_DRIVER=s3")
+	}
+	if _, err := url.Parse(s.Endpoint); err != nil {
+		return s, fmt.Errorf("S3_ENDPOINT tidak valid: %w", err)
+	}
+	return s, nil
+}
+
+func (s S3Storage) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	header := http.Header{}
+	header.Set("Content-Type", contentType)
+	return s.do(ctx, http.MethodPut, key, data, header, http.StatusOK)
+}
+
+func (s S3Storage) Delete(ctx context.Context, key string) error {
+	// S3 membalas 204 walaupun object tidak ada; sebagian layanan kompatibel membalas 404
+	return s.do(ctx, http.MethodDelete, key, nil, http.Header{}, http.StatusNoContent, http.StatusOK, http.StatusNotFound)
+}
+
+func (s S3Storage) URL(key string) string {
+	if s.PublicURL != "" {
+		return joinURL(s.PublicURL, key)
+	}
+	return s.objectURL(key).String()
+}
+
+// objectURL menyusun URL object sesuai gaya path atau virtual-hosted
+func (s S3Storage) objectURL(key string) *url.URL {
+	u, _ := url.Parse(s.Endpoint)
+	base := strings.TrimRight(u.Path, "/")
+	path, rawPath := "/"+key, "/"+uriEscape(key, false)
+	if s.PathStyle {
+		path, rawPath = "/"+s.Bucket+path, "/"+uriEscape(s.Bucket, true)+rawPath
+	} else {
+		u.Host = s.Bucket + "." + u.Host
+	}
+	u.Path = base + path
+	u.RawPath = base + rawPath
+	return u
+}
+
+func (s S3Storage) do(ctx context.Context, method, key string, body []byte, header http.Header, okStatus ...int) error {
+	u := s.objectURL(key)
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header = header
+	req.ContentLength = int64(len(body))
+	s.sign(req, body, time.Now().UTC())
+
+	resp, err := s3Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	for _, status := range okStatus {
+		if resp.StatusCode == status {
+			return nil
+		}
+	}
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("storage: %s %s gagal dengan status %d: %s", method, key, resp.StatusCode, strings.TrimSpace(string(detail)))
+}
+
+// sign menambahkan header Authorization AWS Signature Version 4
+func (s S3Storage) sign(req *http.Request, body []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	// Header yang ditandatangani: host dan semua header yang sudah diset
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.SecretKey), date)
+	key = hmacSHA256(key, s.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.AccessKey, scope, signedHeaders, signature))
+}
+
+// uriEscape meng-encode path sesuai aturan SigV4: hanya karakter unreserved
+// yang tidak di-encode, dan "/" dipertahankan kecuali encodeSlash
+func uriEscape(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
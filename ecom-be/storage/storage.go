@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LocalURLPath adalah path tempat server menyajikan file dari LocalStorage
+const LocalURLPath = "/uploads"
+
+// Storage menyimpan file publik seperti gambar produk. Key berupa path relatif
+// dengan pemisah "/", mis. products/12/abc.jpg.
+type Storage interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+}
+
+// Default adalah storage yang dipakai aplikasi, diatur oleh Setup
+var Default Storage = LocalStorage{Dir: "uploads", PublicURL: LocalURLPath}
+
+// Setup memilih implementasi storage berdasarkan STORAGE_DRIVER (local, s3)
+func Setup() error {
+	switch strings.ToLower(os.Getenv("STORAGE_DRIVER")) {
+	case "s3":
+		s, err := newS3StorageFromEnv()
+		if err != nil {
+			return err
+		}
+		Default = s
+	case "", "local":
+		dir := os.Getenv("STORAGE_LOCAL_DIR")
+		if dir == "" {
+			dir = "uploads"
+		}
+		publicURL := os.Getenv("STORAGE_PUBLIC_URL")
+		if publicURL == "" {
+			publicURL = LocalURLPath
+		}
+		Default = LocalStorage{Dir: dir, PublicURL: publicURL}
+	default:
+		return fmt.Errorf("STORAGE_DRIVER %q tidak dikenal", os.Getenv("STORAGE_DRIVER"))
+	}
+	return nil
+}
+
+// Put menyimpan file melalui storage default
+func Put(ctx context.Context, key string, data []byte, contentType string) error {
+	return Default.Put(ctx, key, data, contentType)
+}
+
+// Delete menghapus file melalui storage default. File yang tidak ada tidak dianggap error.
+func Delete(ctx context.Context, key string) error {
+	return Default.Delete(ctx, key)
+}
+
+// URL mengembalikan URL publik file dari storage default
+func URL(key string) string {
+	return Default.URL(key)
+}
+
+// joinURL menggabungkan base URL dan key tanpa garis miring ganda
+func joinURL(base, key string) string {
+	return strings.TrimRight(base, "/") + "/" + strings.TrimLeft(key, "/")
+}
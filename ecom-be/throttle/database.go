@@ -0,0 +1,85 @@
+package throttle
+
+import (
+	"ecom-be/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DBStore menyimpan status di tabel login_throttles sehingga bisa dibagi
+// oleh beberapa instance server
+type DBStore struct {
+	db *gorm.DB
+}
+
+func NewDBStore(db *gorm.DB) *DBStore {
+	return &DBStore{db: db}
+}
+
+func (s *DBStore) Get(key string) (State, error) {
+	var row models.LoginThrottle
+	err := s.db.Where("`key` = ?", key).First(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return State{}, nil
+	}
+	if err != nil {
+		return State{}, err
+	}
+	return rowState(row), nil
+}
+
+func (s *DBStore) RecordFailure(key string, policy Policy, now time.Time) (State, error) {
+	var state State
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Pastikan baris ada, lalu kunci agar instance lain menunggu
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.LoginThrottle{Key: key, LastFailureAt: now}).Error; err != nil {
+			return err
+		}
+
+		var row models.LoginThrottle
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("`key` = ?", key).First(&row).Error; err != nil {
+			return err
+		}
+
+		// Hitungan direset jika kegagalan terakhir sudah di luar window
+		locked := row.LockedUntil != nil && row.LockedUntil.After(now)
+		if now.Sub(row.LastFailureAt) > policy.Window && !locked {
+			row.Failures = 0
+			row.LockedUntil = nil
+		}
+
+		row.Failures++
+		row.LastFailureAt = now
+		if lock := policy.LockDuration(row.Failures); lock > 0 {
+			until := now.Add(lock)
+			row.LockedUntil = &until
+		}
+
+		if err := tx.Save(&row).Error; err != nil {
+			return err
+		}
+
+		state = rowState(row)
+		return nil
+	})
+
+	return state, err
+}
+
+func (s *DBStore) Reset(key string) error {
+	return s.db.Where("`key` = ?", key).Delete(&models.LoginThrottle{}).Error
+}
+
+func rowState(row models.LoginThrottle) State {
+	state := State{Failures: row.Failures}
+	if row.LockedUntil != nil {
+		state.LockedUntil = *row.LockedUntil
+	}
+	return state
+}
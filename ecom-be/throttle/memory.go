@@ -0,0 +1,77 @@
+package throttle
+
+import (
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	state         State
+	lastFailureAt time.Time
+	window        time.Duration
+}
+
+// MemoryStore menyimpan status di memori proses; cocok untuk satu instance
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]*memoryEntry
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: map[string]*memoryEntry{}}
+}
+
+func (s *MemoryStore) Get(key string) (State, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || s.expired(entry, time.Now()) {
+		return State{}, nil
+	}
+	return entry.state, nil
+}
+
+func (s *MemoryStore) RecordFailure(key string, policy Policy, now time.Time) (State, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune(now)
+
+	entry, ok := s.entries[key]
+	if !ok || s.expired(entry, now) {
+		entry = &memoryEntry{}
+		s.entries[key] = entry
+	}
+
+	entry.state.Failures++
+	entry.lastFailureAt = now
+	entry.window = policy.Window
+	if lock := policy.LockDuration(entry.state.Failures); lock > 0 {
+		entry.state.LockedUntil = now.Add(lock)
+	}
+
+	return entry.state, nil
+}
+
+func (s *MemoryStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// expired mengecek apakah entri sudah melewati window dan tidak sedang terkunci
+func (s *MemoryStore) expired(entry *memoryEntry, now time.Time) bool {
+	return now.Sub(entry.lastFailureAt) > entry.window && !entry.state.LockedUntil.After(now)
+}
+
+// prune membuang entri kedaluwarsa agar map tidak tumbuh tanpa batas
+func (s *MemoryStore) prune(now time.Time) {
+	for key, entry := range s.entries {
+		if s.expired(entry, now) {
+			delete(s.entries, key)
+		}
+	}
+}
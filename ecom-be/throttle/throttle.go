@@ -0,0 +1,81 @@
+// Package throttle melacak percobaan gagal per kunci (mis. email atau IP)
+// dan menghitung penguncian sementara dengan exponential backoff.
+package throttle
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Policy menentukan kapan sebuah kunci mulai dikunci dan berapa lama
+type Policy struct {
+	// FreeAttempts adalah jumlah kegagalan sebelum penguncian pertama
+	FreeAttempts int
+	// BaseDelay adalah lama penguncian pertama, berlipat dua setiap kegagalan berikutnya
+	BaseDelay time.Duration
+	// MaxDelay adalah batas atas lama penguncian
+	MaxDelay time.Duration
+	// Window adalah jeda tanpa kegagalan sebelum hitungan direset
+	Window time.Duration
+}
+
+// LockDuration menghitung lama penguncian setelah failures kali gagal
+func (p Policy) LockDuration(failures int) time.Duration {
+	over := failures - p.FreeAttempts
+	if over <= 0 {
+		return 0
+	}
+
+	delay := p.BaseDelay
+	for i := 1; i < over; i++ {
+		delay *= 2
+		if delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	if delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return delay
+}
+
+// State adalah status percobaan gagal sebuah kunci
+type State struct {
+	Failures    int
+	LockedUntil time.Time
+}
+
+// RetryAfter mengembalikan sisa waktu penguncian, atau 0 jika tidak terkunci
+func (s State) RetryAfter(now time.Time) time.Duration {
+	if s.LockedUntil.After(now) {
+		return s.LockedUntil.Sub(now)
+	}
+	return 0
+}
+
+// Store menyimpan status percobaan gagal
+type Store interface {
+	// Get mengembalikan status kunci saat ini
+	Get(key string) (State, error)
+	// RecordFailure menambah hitungan gagal dan menghitung ulang penguncian
+	RecordFailure(key string, policy Policy, now time.Time) (State, error)
+	// Reset menghapus status kunci setelah percobaan berhasil
+	Reset(key string) error
+}
+
+// Default adalah store yang dipakai aplikasi, diatur oleh Setup
+var Default Store = NewMemoryStore()
+
+// Setup memilih store berdasarkan LOGIN_THROTTLE_STORE (memory, database).
+// Gunakan database jika server dijalankan lebih dari satu instance.
+func Setup(db *gorm.DB) {
+	switch strings.ToLower(os.Getenv("LOGIN_THROTTLE_STORE")) {
+	case "database", "db":
+		Default = NewDBStore(db)
+	default:
+		Default = NewMemoryStore()
+	}
+}